@@ -22,32 +22,55 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/martinnirtl/hosts-cli/internal/helpers"
 	"github.com/martinnirtl/hosts-cli/pkg/files"
 	"github.com/spf13/cobra"
 )
 
 var (
-	user         string
-	identityFile string
-	// importIdentityFilesGlob string
+	user               string
+	identityFile       string
+	port               string
+	jumpHost           string
+	tags               []string
+	ttl                time.Duration
+	owner              string
+	cidr               string
+	nameTemplates      []string
+	hostKey            string
+	hostKeyFile        string
+	hashHostKeys       bool
+	strictHostKey      bool
+	importIdentityFile string
+	generateKey        bool
+	keyType            string
+	askPassphrase      bool
 )
 
-// TODO add option to copy ssh keys into ~/.ssh and configure host block accordingly
-
 // addCmd represents the add command
 var addCmd = &cobra.Command{
-	Use:   "add ADDRESS ALIASES...",
+	Use:   "add [ADDRESS ALIASES...]",
 	Short: "Add address mappings to ssh-config and hosts file",
 	Long: `Add address mappings to ssh-config and hosts file. Address can be an IP or a domain. 
-  Makes your life easier!
+  Makes your life easier! Run without arguments for a step by step wizard.
+  Ranges add one entry per value, e.g. 'hosts add 10.0.1.[10-19] node-[0-9] node-[0-9].lab'. Use --cidr with
+  --name-template to add all addresses of a network, e.g. '--cidr 10.0.1.0/28 --name-template node-{n}'.
+  Use --host-key or --host-key-file to pin known host keys in known_hosts instead of trusting on first use.
+  Use --generate-key to create a new key pair for the host; its public key is printed for authorized_keys.
     Don't forget the sudo!`,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		var comps []string
 		if len(args) == 0 {
-			comps = cobra.AppendActiveHelp(comps, "Expecting address/IP")
+			comps = cobra.AppendActiveHelp(comps, "Expecting address/IP or enter key for the wizard")
 		} else if len(args) == 1 {
 			comps = cobra.AppendActiveHelp(comps, "Expecting one or more host names")
 		} else {
@@ -55,7 +78,26 @@ var addCmd = &cobra.Command{
 		}
 		return comps, cobra.ShellCompDirectiveNoFileComp
 	},
-	Args: cobra.MinimumNArgs(2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cidr != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("accepts no args with --cidr, use --name-template for the aliases")
+			}
+			if len(nameTemplates) == 0 {
+				return fmt.Errorf("--cidr requires at least one --name-template")
+			}
+
+			return nil
+		}
+		if len(nameTemplates) > 0 {
+			return fmt.Errorf("--name-template requires --cidr")
+		}
+		if len(args) == 0 && helpers.IsTerminal() {
+			return nil // wizard
+		}
+
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		err := getFilePaths()
 		if err != nil {
@@ -64,44 +106,118 @@ var addCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if etcHosts {
-			hosts, err := files.GetHosts(hostsFilePath)
+		if len(args) == 0 && cidr == "" {
+			args, err = runAddWizard(cmd)
 			if err != nil {
-				cmd.Printf("Error reading file: %v", err)
+				exitOnPromptError(cmd, err)
+			}
+			if args == nil {
+				cmd.Println("Aborted. No files changed!")
 
-				os.Exit(1)
+				return
 			}
+		}
 
-			hosts.AddHost(args[0], args[1:])
+		var entries [][]string
+		if cidr != "" {
+			entries, err = cidrEntries(cidr, nameTemplates)
+		} else {
+			entries, err = expandEntries(args)
+		}
+		if err != nil {
+			cmd.Printf("%v\n", err)
+
+			os.Exit(1)
+		}
 
-			if !dryRun {
-				if err := hosts.Write(); err != nil {
-					cmd.Printf("Error writing file %s: %v", hostsFilePath, err)
+		if importIdentityFile != "" && identityFile != "" {
+			cmd.Println("Use either --identity-file or --import-identity-file!")
 
-					os.Exit(1)
-				}
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("key-type") && !generateKey {
+			cmd.Println("--key-type requires --generate-key!")
+
+			os.Exit(1)
+		}
+		if askPassphrase && !generateKey {
+			cmd.Println("--passphrase requires --generate-key!")
+
+			os.Exit(1)
+		}
+		if askPassphrase && !helpers.IsTerminal() {
+			cmd.Println("--passphrase requires a terminal!")
+
+			os.Exit(1)
+		}
+		if generateKey {
+			if identityFile != "" || importIdentityFile != "" {
+				cmd.Println("Use either --generate-key, --identity-file or --import-identity-file!")
+
+				os.Exit(1)
+			}
+			if !helpers.SliceContains(helpers.KeyTypes, keyType) {
+				cmd.Printf("Unsupported key type '%s'. Use one of %s!\n", keyType, strings.Join(helpers.KeyTypes, ", "))
+
+				os.Exit(1)
+			}
+			if len(entries) > 1 {
+				cmd.Println("Keys can only be generated when adding a single host!")
+
+				os.Exit(1)
 			}
+		}
+
+		opts := addFlagOptions()
+		for _, entry := range entries {
+			if err := opts.validate(entry[0], entry[1:]); err != nil {
+				cmd.Printf("%v\n", err)
 
-			if dryRun {
-				cmd.Print(helpers.PrintFileWithSpacer(hostsFilePath, hosts))
+				os.Exit(1)
 			}
 		}
 
-		sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+		keys, err := readHostKeys()
 		if err != nil {
-			cmd.Printf("Error reading file: %v", err)
+			cmd.Printf("%v\n", err)
 
 			os.Exit(1)
 		}
+		if len(keys) > 0 && len(entries) > 1 {
+			cmd.Println("Host keys can only be pinned when adding a single host!")
 
-		sshConfig.AddHost(args[1:], args[0], user, identityFile)
+			os.Exit(1)
+		}
 
-		if !dryRun {
-			sshConfig.Write()
+		var keyFiles []file
+		if importIdentityFile != "" {
+			opts.IdentityFile, keyFiles = importIdentity(cmd, importIdentityFile)
 		}
+		var publicKey []byte
+		if generateKey {
+			opts.IdentityFile, publicKey, keyFiles = generateIdentity(cmd, keyType, entries[0][1])
+		}
+
+		hosts, sshConfig := readFiles(cmd)
+
+		for _, entry := range entries {
+			if err := addEntries(cmd, hosts, sshConfig, entry[0], entry[1:], opts); err != nil {
+				cmd.Printf("%v\n", err)
 
-		if dryRun {
-			cmd.Print(helpers.PrintFile(sshConfigFilePath, sshConfig))
+				os.Exit(1)
+			}
+		}
+
+		targets := append(targetFiles(hosts, sshConfig), keyFiles...)
+		if len(keys) > 0 {
+			targets = append(targets, pinHostKeys(cmd, entries[0], opts.Port, keys))
+		}
+
+		replaceFiles(cmd, targets...)
+
+		if publicKey != nil {
+			cmd.Printf("Add the public key to ~/.ssh/authorized_keys of %s:\n", entries[0][1])
+			fmt.Fprint(cmd.OutOrStdout(), string(publicKey))
 		}
 	},
 }
@@ -113,5 +229,614 @@ func init() {
 
 	flags.StringVarP(&user, "user", "u", "", "Set User property in SSH config Host block")
 	flags.StringVarP(&identityFile, "identity-file", "i", "", "Use identity file; e.g. ~/.ssh/custom")
-	// flags.StringVarP(&importIdentityFilesGlob, "import-idenity-files-glob", "j", "", "Import and use identity file; moves file to ~/.ssh/")
+	flags.StringVarP(&port, "port", "p", "", "Set Port property in SSH config Host block")
+	flags.StringVarP(&jumpHost, "jump-host", "J", "", "Set ProxyJump property in SSH config Host block; e.g. user@bastion:22")
+	flags.DurationVar(&ttl, "ttl", 0, "Remove entries with 'hosts gc' after the given duration; e.g. 4h or 30m")
+	flags.StringSliceVarP(&tags, "tag", "t", nil, "Tag entries for selecting them later, e.g. with 'hosts rm --tag'; repeatable")
+	flags.StringVar(&owner, "owner", "", "Register an owner sharing the entries; 'hosts rm --owner' keeps them until the last owner is removed")
+	flags.BoolVar(&generateKey, "generate-key", false, "Generate a key pair in ~/.ssh named after the first alias and use it as identity file")
+	flags.StringVar(&keyType, "key-type", "ed25519", "Type of the key pair of --generate-key; ed25519, rsa or ecdsa")
+	flags.BoolVar(&askPassphrase, "passphrase", false, "Prompt for a passphrase protecting the key of --generate-key")
+	flags.StringVar(&hostKey, "host-key", "", "Pin the host key in known_hosts for aliases and address; e.g. 'ssh-ed25519 AAAA...'")
+	flags.StringVar(&hostKeyFile, "host-key-file", "", "Pin the host keys of a file in known_hosts, e.g. a .pub file or ssh-keyscan output")
+	flags.BoolVar(&hashHostKeys, "hash-known-hosts", false, "Hash aliases and address of pinned host keys like 'HashKnownHosts yes'")
+	flags.BoolVar(&strictHostKey, "strict-host-key-checking", false, "Set StrictHostKeyChecking yes in the new Host block")
+	flags.StringVar(&cidr, "cidr", "", "Add an entry for each host address of a network; e.g. 10.0.1.0/28")
+	flags.StringSliceVar(&nameTemplates, "name-template", nil, "Alias of each --cidr entry with {n} replaced by its index starting at 0; e.g. node-{n}; repeatable")
+	flags.StringVarP(&importIdentityFile, "import-identity-file", "j", "", "Copy a private key and its .pub into ~/.ssh with safe permissions and use it as identity file")
+}
+
+// addOptions holds all options of a single add, e.g. from flags or a line of 'hosts batch'
+type addOptions struct {
+	User                  string
+	IdentityFile          string
+	Port                  string
+	JumpHost              string
+	Tags                  []string
+	TTL                   time.Duration
+	Owner                 string
+	StrictHostKeyChecking bool
+}
+
+func addFlagOptions() *addOptions {
+	return &addOptions{
+		User:                  user,
+		IdentityFile:          identityFile,
+		Port:                  port,
+		JumpHost:              jumpHost,
+		Tags:                  tags,
+		TTL:                   ttl,
+		Owner:                 owner,
+		StrictHostKeyChecking: strictHostKey,
+	}
+}
+
+// validate checks address, aliases and all options before anything gets written
+func (opts *addOptions) validate(address string, aliases []string) error {
+	if !helpers.IsValidAddress(address) {
+		return fmt.Errorf("'%s' is not a valid IP address or domain", address)
+	}
+	for _, alias := range aliases {
+		if !helpers.IsValidHostname(alias) {
+			return fmt.Errorf("'%s' is not a valid host name", alias)
+		}
+	}
+	if opts.Port != "" && !helpers.IsValidPort(opts.Port) {
+		return fmt.Errorf("Port must be a number between 1 and 65535")
+	}
+	for _, tag := range opts.Tags {
+		if !helpers.IsValidTag(tag) {
+			return fmt.Errorf("'%s' is not a valid tag. Use letters, digits, '.', '_' and '-'!", tag)
+		}
+	}
+	if opts.Owner != "" && !helpers.IsValidOwner(opts.Owner) {
+		return fmt.Errorf("'%s' is not a valid owner. Use letters, digits, '.', '_' and '-'!", opts.Owner)
+	}
+	if opts.TTL < 0 {
+		return fmt.Errorf("TTL must not be negative!")
+	}
+
+	return nil
+}
+
+// addEntries adds address and aliases to hosts (if loaded) and sshConfig. With an owner, existing entries
+// of exactly address and aliases are shared instead of adding duplicates. Options of a shared Host block must
+// be unset or equal.
+func addEntries(cmd *cobra.Command, hosts *files.Hosts, sshConfig *files.SSHConfig, address string, aliases []string, opts *addOptions) error {
+	expires := time.Now().Add(opts.TTL)
+
+	block := findSharedBlock(sshConfig, address, aliases, opts.Owner)
+	if block != nil {
+		if err := shareHostBlock(block, opts); err != nil {
+			return err
+		}
+	}
+
+	if hosts != nil {
+		host := findSharedHost(hosts, address, aliases, opts.Owner)
+		if host != nil {
+			cmd.Printf("Adding owner '%s' to existing entry in %s\n", opts.Owner, hostsFilePath)
+		} else {
+			host, _ = hosts.AddHost(address, aliases)
+		}
+		markAdded(host, opts, expires)
+	}
+
+	if block != nil {
+		cmd.Printf("Adding owner '%s' to existing block in %s\n", opts.Owner, sshConfigFilePath)
+	} else {
+		block = addHostBlock(sshConfig, address, aliases, opts)
+	}
+	markAdded(block, opts, expires)
+
+	return nil
+}
+
+func addHostBlock(sshConfig *files.SSHConfig, address string, aliases []string, opts *addOptions) *files.HostBlock {
+	block, _ := sshConfig.AddHost(aliases, address, "", "")
+	for _, prop := range opts.blockProps() {
+		block.SetProp(prop[0], prop[1])
+	}
+
+	return block
+}
+
+// blockProps returns the Host block properties of opts as keyword and value pairs in the order they are added
+func (opts *addOptions) blockProps() [][2]string {
+	props := make([][2]string, 0, 6)
+	if opts.User != "" {
+		props = append(props, [2]string{"User", opts.User})
+	}
+	if opts.IdentityFile != "" {
+		props = append(props, [2]string{"IdentityFile", opts.IdentityFile}, [2]string{"IdentitiesOnly", "yes"})
+	}
+	if opts.Port != "" {
+		props = append(props, [2]string{"Port", opts.Port})
+	}
+	if opts.JumpHost != "" {
+		props = append(props, [2]string{"ProxyJump", opts.JumpHost})
+	}
+	if opts.StrictHostKeyChecking {
+		props = append(props, [2]string{"StrictHostKeyChecking", "yes"})
+	}
+
+	return props
+}
+
+// shareHostBlock sets the options of another owner on a shared Host block, failing if one of them differs
+// from the block before anything is changed
+func shareHostBlock(block *files.HostBlock, opts *addOptions) error {
+	props := opts.blockProps()
+	for _, prop := range props {
+		if value, ok := block.GetProp(prop[0]); ok && value != prop[1] {
+			return fmt.Errorf("Shared Host block '%s' has %s %s instead of %s. Use the same options as the other owners!", strings.Join(block.Hosts, " "), prop[0], value, prop[1])
+		}
+	}
+	for _, prop := range props {
+		block.SetProp(prop[0], prop[1])
+	}
+
+	return nil
+}
+
+// addedEntry is implemented by hosts file entries and ssh config blocks
+type addedEntry interface {
+	SetManaged(managed bool)
+	AddTags(tags ...string)
+	SetExpires(expires time.Time)
+	AddOwner(owner string, expires time.Time)
+}
+
+// markAdded stores the metadata of all add options in entry. The TTL of an owner only expires its share of
+// the entry, so that entries shared with other owners are kept.
+func markAdded(entry addedEntry, opts *addOptions, expires time.Time) {
+	entry.SetManaged(true)
+	entry.AddTags(opts.Tags...)
+	if opts.TTL <= 0 {
+		expires = time.Time{}
+	}
+	if opts.Owner != "" {
+		entry.AddOwner(opts.Owner, expires)
+	} else if opts.TTL > 0 {
+		entry.SetExpires(expires)
+	}
+}
+
+// findSharedHost returns the managed entry mapping exactly address and aliases if owner is given
+func findSharedHost(hosts *files.Hosts, address string, aliases []string, owner string) *files.Host {
+	if owner == "" {
+		return nil
+	}
+
+	for _, entry := range hosts.Entries() {
+		if entry.Managed() && entry.Address() == address && sameAliases(entry.Aliases(), aliases) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// findSharedBlock returns the managed Host block of exactly aliases pointing to address if owner is given
+func findSharedBlock(sshConfig *files.SSHConfig, address string, aliases []string, owner string) *files.HostBlock {
+	if owner == "" {
+		return nil
+	}
+
+	for _, block := range sshConfig.FindBlocks(aliases[0]) {
+		hostname, _ := block.GetProp("HostName")
+		if block.Managed() && hostname == address && sameAliases(block.Hosts, aliases) {
+			return block
+		}
+	}
+
+	return nil
+}
+
+// importIdentity returns the path the private key src is imported to in ~/.ssh and the files to create there,
+// which are none if it is there already or in dry-run mode
+func importIdentity(cmd *cobra.Command, src string) (string, []file) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		cmd.Printf("Error retrieving user's home directory: %v", err)
+
+		os.Exit(1)
+	}
+
+	dst, exists, err := helpers.IdentityImportPath(src, filepath.Join(homeDir, ".ssh"))
+	if err != nil {
+		cmd.Printf("Error importing identity file: %v\n", err)
+
+		os.Exit(1)
+	}
+	identityFile := strings.Replace(dst, homeDir, "~", 1)
+
+	switch {
+	case exists:
+		cmd.Printf("Using %s, which is identical to %s\n", dst, src)
+
+		return identityFile, nil
+	case dryRun:
+		cmd.Printf("Would import %s to %s\n", src, dst)
+
+		return identityFile, nil
+	}
+
+	private, err := os.ReadFile(src)
+	if err != nil {
+		cmd.Printf("Error reading file: %v\n", err)
+
+		os.Exit(1)
+	}
+	keyFiles := []file{&newFile{path: dst, content: private, perm: 0600}}
+
+	public, err := os.ReadFile(src + ".pub")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		cmd.Printf("Error reading file: %v\n", err)
+
+		os.Exit(1)
+	}
+	if err == nil {
+		keyFiles = append(keyFiles, &newFile{path: dst + ".pub", content: public, perm: 0644})
+	}
+
+	return identityFile, keyFiles
+}
+
+// generateIdentity creates a key pair of keyType for alias and returns the path of its private key in ~/.ssh,
+// its public key and the files to create there. In dry-run mode, no key pair is created.
+func generateIdentity(cmd *cobra.Command, keyType string, alias string) (string, []byte, []file) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		cmd.Printf("Error retrieving user's home directory: %v", err)
+
+		os.Exit(1)
+	}
+
+	path := filepath.Join(homeDir, ".ssh", fmt.Sprintf("id_%s_%s", keyType, alias))
+	for _, existing := range []string{path, path + ".pub"} {
+		if _, err := os.Stat(existing); err == nil {
+			cmd.Printf("%s already exists. Use --identity-file to use it!\n", existing)
+
+			os.Exit(1)
+		}
+	}
+	identityFile := strings.Replace(path, homeDir, "~", 1)
+
+	if dryRun {
+		cmd.Printf("Would generate %s key pair %s\n", keyType, path)
+
+		return identityFile, nil, nil
+	}
+
+	passphrase := ""
+	if askPassphrase {
+		passphrase, err = promptPassphrase()
+		if err != nil {
+			exitOnPromptError(cmd, err)
+		}
+	}
+
+	private, public, err := helpers.GenerateKeyPair(keyType, alias, passphrase)
+	if err != nil {
+		cmd.Printf("Error generating key pair: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	return identityFile, public, []file{
+		&newFile{path: path, content: private, perm: 0600},
+		&newFile{path: path + ".pub", content: public, perm: 0644},
+	}
+}
+
+func promptPassphrase() (string, error) {
+	passphrase, confirmation := "", ""
+	if err := survey.AskOne(&survey.Password{Message: "Passphrase:"}, &passphrase, survey.WithValidator(survey.Required)); err != nil {
+		return "", err
+	}
+	if err := survey.AskOne(&survey.Password{Message: "Repeat passphrase:"}, &confirmation); err != nil {
+		return "", err
+	}
+	if passphrase != confirmation {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
+
+// readHostKeys returns the keys given by --host-key and --host-key-file
+func readHostKeys() ([]*files.KnownHost, error) {
+	keys := make([]*files.KnownHost, 0, 3)
+	if hostKey != "" {
+		key, err := files.ParseHostKey(hostKey)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid host key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if hostKeyFile != "" {
+		content, err := os.ReadFile(hostKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading file: %v", err)
+		}
+		fileKeys, err := files.ParseHostKeys(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid host keys in %s: %v", hostKeyFile, err)
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	return keys, nil
+}
+
+// pinHostKeys replaces the keys of the aliases and address of entry in known_hosts by keys
+func pinHostKeys(cmd *cobra.Command, entry []string, port string, keys []*files.KnownHost) *files.KnownHosts {
+	knownHosts, err := files.GetKnownHosts(knownHostsFilePath)
+	if err != nil {
+		cmd.Printf("Error reading file: %v", err)
+
+		os.Exit(1)
+	}
+
+	hosts := helpers.UniqueStrings(append(append([]string{}, entry[1:]...), entry[0]))
+	names := make([]string, len(hosts))
+	for i, host := range hosts {
+		names[i] = files.KnownHostsName(host, port)
+	}
+
+	if err := knownHosts.PinKeys(names, keys, hashHostKeys); err != nil {
+		cmd.Printf("Error pinning host keys: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	return knownHosts
+}
+
+// expandEntries expands ranges in address and aliases into one entry per value, pairing the n-th values of
+// all ranges. Args without range are used for every entry.
+func expandEntries(args []string) ([][]string, error) {
+	expanded := make([][]string, len(args))
+	count := 1
+	for i, arg := range args {
+		values, err := helpers.ExpandRange(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 1 {
+			if count > 1 && len(values) != count {
+				return nil, fmt.Errorf("'%s' expands to %d values, but other ranges to %d", arg, len(values), count)
+			}
+			count = len(values)
+		}
+		expanded[i] = values
+	}
+
+	entries := make([][]string, count)
+	for n := range entries {
+		entries[n] = make([]string, len(args))
+		for i, values := range expanded {
+			if len(values) == 1 {
+				entries[n][i] = values[0]
+			} else {
+				entries[n][i] = values[n]
+			}
+		}
+	}
+
+	return entries, uniqueAliases(entries)
+}
+
+// cidrEntries returns an entry for each host address of cidr with aliases from templates
+func cidrEntries(cidr string, templates []string) ([][]string, error) {
+	addresses, err := helpers.ExpandCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([][]string, len(addresses))
+	for n, address := range addresses {
+		entries[n] = []string{address}
+		for _, template := range templates {
+			entries[n] = append(entries[n], strings.ReplaceAll(template, "{n}", strconv.Itoa(n)))
+		}
+	}
+
+	return entries, uniqueAliases(entries)
+}
+
+// uniqueAliases fails if an alias is used by more than one of entries
+func uniqueAliases(entries [][]string) error {
+	seen := make(map[string]int)
+	for n, entry := range entries {
+		for _, alias := range entry[1:] {
+			if other, ok := seen[alias]; ok && other != n {
+				return fmt.Errorf("'%s' would be added by more than one entry; use a range like %s-[0-9]", alias, alias)
+			}
+			seen[alias] = n
+		}
+	}
+
+	return nil
+}
+
+func sameAliases(a []string, b []string) bool {
+	a, b = helpers.UniqueStrings(a), helpers.UniqueStrings(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for _, alias := range a {
+		if !helpers.SliceContains(b, alias) {
+			return false
+		}
+	}
+
+	return true
+}
+
+const noIdentityFile = "(none)"
+
+type addAnswers struct {
+	Address      string
+	Aliases      string
+	User         string
+	Port         string
+	IdentityFile string
+	JumpHost     string
+	Tags         string
+	EtcHosts     bool
+}
+
+// runAddWizard prompts for all add options and returns the resulting args or nil if the user aborted
+func runAddWizard(cmd *cobra.Command) ([]string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	identityFiles := []string{noIdentityFile}
+	if found, err := helpers.ListIdentityFiles(filepath.Join(homeDir, ".ssh")); err == nil {
+		for _, file := range found {
+			identityFiles = append(identityFiles, strings.Replace(file, homeDir, "~", 1))
+		}
+	}
+	if identityFile != "" && !helpers.SliceContains(identityFiles, identityFile) {
+		identityFiles = append(identityFiles, identityFile)
+	}
+	defaultIdentityFile := noIdentityFile
+	if identityFile != "" {
+		defaultIdentityFile = identityFile
+	}
+
+	questions := []*survey.Question{
+		{
+			Name:     "address",
+			Prompt:   &survey.Input{Message: "Address (IP or domain):"},
+			Validate: validateWith(helpers.IsValidAddress, "not a valid IP address or domain"),
+		},
+		{
+			Name:     "aliases",
+			Prompt:   &survey.Input{Message: "Aliases (separated by spaces):", Help: "The first alias is used as primary host name"},
+			Validate: validateAliases,
+		},
+		{
+			Name:   "user",
+			Prompt: &survey.Input{Message: "User (optional):", Default: user},
+			Validate: func(ans interface{}) error {
+				if strings.ContainsAny(ans.(string), " \t") {
+					return fmt.Errorf("user must not contain whitespace")
+				}
+				return nil
+			},
+		},
+		{
+			Name:     "port",
+			Prompt:   &survey.Input{Message: "Port (optional):", Default: port},
+			Validate: optional(validateWith(helpers.IsValidPort, "port must be a number between 1 and 65535")),
+		},
+		{
+			Name:   "identityFile",
+			Prompt: &survey.Select{Message: "Identity file:", Options: identityFiles, Default: defaultIdentityFile},
+		},
+		{
+			Name:   "jumpHost",
+			Prompt: &survey.Input{Message: "Jump host (optional):", Default: jumpHost, Help: "Set as ProxyJump; e.g. user@bastion:22"},
+			Validate: func(ans interface{}) error {
+				if strings.ContainsAny(strings.TrimSpace(ans.(string)), " \t") {
+					return fmt.Errorf("jump host must not contain whitespace; separate multiple hops by comma")
+				}
+				return nil
+			},
+		},
+		{
+			Name:   "tags",
+			Prompt: &survey.Input{Message: "Tags (optional, separated by spaces):", Default: strings.Join(tags, " ")},
+			Validate: func(ans interface{}) error {
+				for _, tag := range strings.Fields(ans.(string)) {
+					if !helpers.IsValidTag(tag) {
+						return fmt.Errorf("'%s' is not a valid tag", tag)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:   "etcHosts",
+			Prompt: &survey.Confirm{Message: fmt.Sprintf("Add entry to %s (requires sudo)?", hostsFilePath), Default: etcHosts},
+		},
+	}
+
+	answers := addAnswers{}
+	if err := survey.Ask(questions, &answers); err != nil {
+		return nil, err
+	}
+
+	user = strings.TrimSpace(answers.User)
+	port = strings.TrimSpace(answers.Port)
+	jumpHost = strings.TrimSpace(answers.JumpHost)
+	tags = strings.Fields(answers.Tags)
+	identityFile = answers.IdentityFile
+	if identityFile == noIdentityFile {
+		identityFile = ""
+	}
+	etcHosts = answers.EtcHosts
+
+	address := strings.TrimSpace(answers.Address)
+	aliases := strings.Fields(answers.Aliases)
+
+	if etcHosts {
+		host, _ := (&files.Hosts{}).AddHost(address, aliases)
+		markAdded(host, addFlagOptions(), time.Now().Add(ttl))
+		cmd.Print(helpers.PrintFileWithSpacer(hostsFilePath, host.String()+"\n"))
+	}
+	block := addHostBlock(&files.SSHConfig{}, address, aliases, addFlagOptions())
+	markAdded(block, addFlagOptions(), time.Now().Add(ttl))
+	cmd.Print(helpers.PrintFile(sshConfigFilePath, block))
+
+	if dryRun {
+		return append([]string{address}, aliases...), nil
+	}
+
+	confirmed := false
+	if err := survey.AskOne(&survey.Confirm{Message: "Save?", Default: true}, &confirmed); err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, nil
+	}
+
+	return append([]string{address}, aliases...), nil
+}
+
+func validateWith(valid func(string) bool, message string) survey.Validator {
+	return func(ans interface{}) error {
+		if !valid(strings.TrimSpace(ans.(string))) {
+			return errors.New(message)
+		}
+		return nil
+	}
+}
+
+func optional(validator survey.Validator) survey.Validator {
+	return func(ans interface{}) error {
+		if strings.TrimSpace(ans.(string)) == "" {
+			return nil
+		}
+		return validator(ans)
+	}
+}
+
+func validateAliases(ans interface{}) error {
+	aliases := strings.Fields(ans.(string))
+	if len(aliases) == 0 {
+		return fmt.Errorf("at least one alias is required")
+	}
+	for _, alias := range aliases {
+		if !helpers.IsValidHostname(alias) {
+			return fmt.Errorf("'%s' is not a valid host name", alias)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,190 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/martinnirtl/hosts-cli/internal/diff"
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+var (
+	specFile    string
+	prune       bool
+	autoApprove bool
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply -f SPEC",
+	Short: "Apply a spec file declaring host entries of ssh-config and hosts file",
+	Long: `Apply a YAML, JSON or TOML spec declaring hosts file entries and ssh config Host blocks, e.g.
+
+  name: lab
+  hosts:
+    - address: 10.0.1.10
+      aliases: [node-0, node-0.lab]
+      tags: [lab]
+  ssh:
+    - hosts: [node-0]
+      options:
+        HostName: 10.0.1.10
+        User: admin
+
+  Prints a plan of all changes and asks for confirmation unless --auto-approve is given, which is required
+  when not running in a terminal.
+  Applying the same spec twice changes nothing. Entries written by the spec which are no longer declared
+  are removed with --prune. Declared aliases already used by other entries fail the plan unless --force is given.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) >= 0 {
+			comps = cobra.AppendActiveHelp(comps, "No args expected - use -f to provide the spec file")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		spec, err := files.GetSpec(specFile)
+		if err != nil {
+			cmd.Printf("Error reading spec: %v\n", err)
+
+			os.Exit(1)
+		}
+
+		hosts, sshConfig := readFiles(cmd)
+		if hosts == nil && len(spec.Hosts) > 0 {
+			cmd.Printf("Skipping %d hosts file entries. Use --etc-hosts to apply them!\n", len(spec.Hosts))
+		}
+
+		plan, err := files.PlanSpec(spec, hosts, sshConfig, prune, force)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			cmd.Println("Use --force to replace those entries!")
+
+			os.Exit(1)
+		}
+
+		cmd.Print(formatPlan(plan))
+		if plan.Empty() || dryRun {
+			return
+		}
+
+		if !autoApprove {
+			if !helpers.IsTerminal() {
+				cmd.Println("Not running in a terminal. Use --auto-approve to apply without confirmation!")
+
+				os.Exit(1)
+			}
+
+			confirmed := false
+			if err := survey.AskOne(&survey.Confirm{Message: "Apply changes?"}, &confirmed); err != nil {
+				exitOnPromptError(cmd, err)
+			}
+			if !confirmed {
+				cmd.Println("Aborted. No files changed!")
+
+				return
+			}
+		}
+
+		plan.Apply(hosts, sshConfig)
+
+		replaceFiles(cmd, targetFiles(hosts, sshConfig)...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	flags := applyCmd.Flags()
+	flags.StringVarP(&specFile, "file", "f", "", "Spec file (.yaml, .yml, .json or .toml)")
+	flags.BoolVar(&prune, "prune", false, "Remove entries written by the spec which are no longer declared")
+	flags.BoolVar(&force, "force", false, "Replace entries using declared aliases which were not written by the spec")
+	flags.BoolVar(&autoApprove, "auto-approve", false, "Apply without asking for confirmation")
+	applyCmd.MarkFlagRequired("file")
+}
+
+// formatPlan prints all changes of plan in the style of a Terraform plan
+func formatPlan(plan *files.Plan) string {
+	var output strings.Builder
+
+	if plan.Empty() {
+		output.WriteString(fmt.Sprintf("No changes. Files match spec '%s'.\n", plan.Spec))
+	} else {
+		output.WriteString(fmt.Sprintf("hosts-cli will perform the following actions for spec '%s':\n", plan.Spec))
+		for _, change := range plan.Hosts {
+			output.WriteString(formatChange(change, hostsFilePath))
+		}
+		for _, change := range plan.Blocks {
+			output.WriteString(formatChange(change, sshConfigFilePath))
+		}
+
+		created, updated, destroyed := plan.Counts()
+		output.WriteString(fmt.Sprintf("\nPlan: %d to add, %d to change, %d to destroy.\n", created, updated, destroyed))
+	}
+
+	if plan.Undeclared > 0 {
+		output.WriteString(fmt.Sprintf("Keeping %d entries which are no longer declared. Use --prune to remove them!\n", plan.Undeclared))
+	}
+
+	return output.String()
+}
+
+func formatChange(change *files.Change, path string) string {
+	var output strings.Builder
+
+	switch change.Action {
+	case files.Create:
+		fmt.Fprintf(&output, "\n  # %s in %s will be created\n", change.Name, path)
+	case files.Update:
+		fmt.Fprintf(&output, "\n  # %s in %s will be updated in-place\n", change.Name, path)
+	case files.Destroy:
+		fmt.Fprintf(&output, "\n  # %s in %s will be destroyed\n", change.Name, path)
+	}
+
+	for _, line := range diff.Lines(planLines(change.Before), planLines(change.After)) {
+		switch line.Op {
+		case diff.Insert:
+			output.WriteString("  + " + line.Text + "\n")
+		case diff.Delete:
+			output.WriteString("  - " + line.Text + "\n")
+		default:
+			output.WriteString("    " + line.Text + "\n")
+		}
+	}
+
+	return output.String()
+}
+
+func planLines(entry string) []string {
+	if entry == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(entry, "\n"), "\n")
+}
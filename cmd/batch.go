@@ -0,0 +1,361 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	batchFile   string
+	batchBackup string
+)
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch [-f FILE]",
+	Short: "Apply a sequence of operations from stdin or a file in one go",
+	Long: `Apply a sequence of add, rm, set, unset, disable and enable operations read from stdin or a file, e.g.
+
+  add 10.0.1.10 node-0 node-0.lab -u admin -t lab
+  set node-0 Port 2222
+  rm old-node --force
+  {"op": "add", "address": "10.0.1.11", "aliases": ["node-1"], "tags": ["lab"]}
+
+  Lines take the arguments and flags of the respective command; lines starting with '{' are JSON objects
+  with the fields op, address, aliases, alias, keyword, value, user, identityFile, port, jumpHost, tags,
+  ttl, owner and force. Empty lines and lines starting with '#' are ignored.
+  All operations are validated first and then applied at once: each file is backed up and written a single
+  time. If one operation fails, no file is changed!`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) >= 0 {
+			comps = cobra.AppendActiveHelp(comps, "No args expected - pipe operations to stdin or use -f")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		input := cmd.InOrStdin()
+		if batchFile != "" && batchFile != "-" {
+			file, err := os.Open(batchFile)
+			if err != nil {
+				cmd.Printf("Error reading file: %v\n", err)
+
+				os.Exit(1)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		ops, err := parseBatch(input)
+		if err != nil {
+			cmd.Printf("Error reading operations: %v\n", err)
+
+			os.Exit(1)
+		}
+		if len(ops) == 0 {
+			cmd.Println("No operations given. Nothing to do!")
+
+			return
+		}
+
+		hosts, sshConfig := readFiles(cmd)
+
+		for _, op := range ops {
+			if err := op.apply(cmd, hosts, sshConfig); err != nil {
+				cmd.Printf("Error in line %d: %v\nNo changes made!\n", op.line, err)
+
+				os.Exit(1)
+			}
+		}
+
+		backupSuffix = batchBackup
+		writeFiles(cmd, hosts, sshConfig)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	flags := batchCmd.Flags()
+	flags.StringVarP(&batchFile, "file", "f", "", "Read operations from file instead of stdin")
+	flags.StringVar(&batchBackup, "backup-suffix", ".bak", "Back up each file to its path with suffix appended before writing; empty to skip")
+}
+
+// batchOp is a single operation of 'hosts batch', given as command line or JSON object
+type batchOp struct {
+	Op           string   `json:"op"`
+	Address      string   `json:"address"`
+	Aliases      []string `json:"aliases"`
+	Alias        string   `json:"alias"`
+	Keyword      string   `json:"keyword"`
+	Value        string   `json:"value"`
+	User         string   `json:"user"`
+	IdentityFile string   `json:"identityFile"`
+	Port         string   `json:"port"`
+	JumpHost     string   `json:"jumpHost"`
+	Tags         []string `json:"tags"`
+	TTL          string   `json:"ttl"`
+	Owner        string   `json:"owner"`
+	Force        bool     `json:"force"`
+
+	line int
+	ttl  time.Duration
+}
+
+// parseBatch reads and validates all operations, reporting all invalid lines at once
+func parseBatch(input io.Reader) ([]*batchOp, error) {
+	ops := make([]*batchOp, 0, 10)
+	problems := make([]string, 0)
+
+	scanner := bufio.NewScanner(input)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var op *batchOp
+		var err error
+		if strings.HasPrefix(line, "{") {
+			op, err = parseBatchJSON(line)
+		} else {
+			op, err = parseBatchLine(line)
+		}
+		if err == nil {
+			err = op.validate()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", n, err))
+			continue
+		}
+
+		op.line = n
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("Invalid operations:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return ops, nil
+}
+
+func parseBatchJSON(line string) (*batchOp, error) {
+	op := &batchOp{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(line)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(op); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// parseBatchLine parses a line taking the same arguments and flags as the command of the operation
+func parseBatchLine(line string) (*batchOp, error) {
+	fields, err := helpers.SplitFields(line)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &batchOp{Op: fields[0]}
+	flags := pflag.NewFlagSet(op.Op, pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	switch op.Op {
+	case "add":
+		flags.StringVarP(&op.User, "user", "u", "", "")
+		flags.StringVarP(&op.IdentityFile, "identity-file", "i", "", "")
+		flags.StringVarP(&op.Port, "port", "p", "", "")
+		flags.StringVarP(&op.JumpHost, "jump-host", "J", "", "")
+		flags.StringSliceVarP(&op.Tags, "tag", "t", nil, "")
+		flags.StringVar(&op.TTL, "ttl", "", "")
+		flags.StringVar(&op.Owner, "owner", "", "")
+	case "rm":
+		flags.StringSliceVarP(&op.Tags, "tag", "t", nil, "")
+		flags.BoolVarP(&op.Force, "force", "f", false, "")
+		flags.StringVar(&op.Owner, "owner", "", "")
+	case "disable", "enable":
+		flags.StringSliceVarP(&op.Tags, "tag", "t", nil, "")
+		flags.BoolVarP(&op.Force, "force", "f", false, "")
+	}
+
+	if err := flags.Parse(fields[1:]); err != nil {
+		return nil, err
+	}
+	args := flags.Args()
+
+	switch op.Op {
+	case "add":
+		if len(args) > 0 {
+			op.Address, op.Aliases = args[0], args[1:]
+		}
+	case "set":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("expecting ALIAS KEYWORD VALUE...")
+		}
+		op.Alias, op.Keyword, op.Value = args[0], args[1], strings.Join(args[2:], " ")
+	case "unset":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expecting ALIAS KEYWORD")
+		}
+		op.Alias, op.Keyword = args[0], args[1]
+	default:
+		op.Aliases = args
+	}
+
+	return op, nil
+}
+
+// validate checks the operation before any of the files is touched
+func (op *batchOp) validate() error {
+	if op.TTL != "" {
+		if op.Op != "add" {
+			return fmt.Errorf("ttl is only supported by add")
+		}
+		ttl, err := time.ParseDuration(op.TTL)
+		if err != nil {
+			return fmt.Errorf("invalid ttl '%s'", op.TTL)
+		}
+		op.ttl = ttl
+	}
+
+	switch op.Op {
+	case "add":
+		if op.Address == "" || len(op.Aliases) == 0 {
+			return fmt.Errorf("expecting ADDRESS ALIASES...")
+		}
+		return op.addOptions().validate(op.Address, op.Aliases)
+	case "rm":
+		if len(op.Aliases) == 0 && len(op.Tags) == 0 && op.Owner == "" {
+			return fmt.Errorf("expecting host names, tags or an owner")
+		}
+		if op.Owner != "" && !helpers.IsValidOwner(op.Owner) {
+			return fmt.Errorf("'%s' is not a valid owner", op.Owner)
+		}
+	case "disable", "enable":
+		if len(op.Aliases) == 0 && len(op.Tags) == 0 {
+			return fmt.Errorf("expecting host names or tags")
+		}
+	case "set", "unset":
+		if op.Alias == "" || op.Keyword == "" {
+			return fmt.Errorf("expecting alias and keyword")
+		}
+		keyword, err := propKeyword(op.Keyword)
+		if err != nil {
+			return err
+		}
+		op.Keyword = keyword
+
+		if op.Op == "unset" {
+			if keyword == "HostName" {
+				return fmt.Errorf("keyword 'HostName' can not be removed, use rm to remove the host")
+			}
+			return nil
+		}
+		if op.Value == "" {
+			return fmt.Errorf("expecting value for %s", keyword)
+		}
+		return validatePropValue(keyword, op.Value)
+	default:
+		return fmt.Errorf("unknown operation '%s'; expecting add, rm, set, unset, disable or enable", op.Op)
+	}
+
+	for _, tag := range op.Tags {
+		if !helpers.IsValidTag(tag) {
+			return fmt.Errorf("'%s' is not a valid tag", tag)
+		}
+	}
+
+	return nil
+}
+
+func (op *batchOp) addOptions() *addOptions {
+	return &addOptions{
+		User:         op.User,
+		IdentityFile: op.IdentityFile,
+		Port:         op.Port,
+		JumpHost:     op.JumpHost,
+		Tags:         op.Tags,
+		TTL:          op.ttl,
+		Owner:        op.Owner,
+	}
+}
+
+// apply performs the operation on the loaded files; hosts is nil unless --etc-hosts is given
+func (op *batchOp) apply(cmd *cobra.Command, hosts *files.Hosts, sshConfig *files.SSHConfig) error {
+	switch op.Op {
+	case "add":
+		if err := addEntries(cmd, hosts, sshConfig, op.Address, op.Aliases, op.addOptions()); err != nil {
+			return err
+		}
+	case "rm":
+		removed := removeEntries(hosts, sshConfig, op.Aliases, op.Tags, op.Force, op.Owner)
+		for _, skipped := range removed.skipped {
+			cmd.Printf("Line %d: skipping %s, which was not created by hosts-cli. Use --force to remove it!\n", op.line, skipped)
+		}
+		if len(removed.hosts) == 0 && len(removed.blocks) == 0 && removed.released == 0 {
+			return fmt.Errorf("no matching entries found")
+		}
+	case "disable", "enable":
+		_, skipped, err := disableEntries(hosts, sshConfig, op.Aliases, op.Tags, op.Op == "disable", op.Force)
+		if err != nil {
+			return err
+		}
+		if skipped > 0 {
+			cmd.Printf("Line %d: skipping %d tagged entries not created by hosts-cli. Use --force to include them!\n", op.line, skipped)
+		}
+	case "set":
+		block, err := hostBlock(sshConfig, op.Alias)
+		if err != nil {
+			return err
+		}
+		sshConfig.SplitHost(block, op.Alias).SetProp(op.Keyword, op.Value)
+	case "unset":
+		block, err := hostBlock(sshConfig, op.Alias)
+		if err != nil {
+			return err
+		}
+		if _, ok := block.GetProp(op.Keyword); ok {
+			sshConfig.SplitHost(block, op.Alias).RemoveProp(op.Keyword)
+		}
+	}
+
+	return nil
+}
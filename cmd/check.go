@@ -0,0 +1,187 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/martinnirtl/hosts-cli/internal/diff"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+// driftExitCode is the exit status of 'hosts check' for drifted files, telling drift apart from errors
+const driftExitCode = 2
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [-f SPEC]",
+	Short: "Detect entries added, removed or changed out-of-band",
+	Long: `Compare ssh-config and hosts file with a spec (-f) or, without a spec, with their state as last written by hosts-cli.
+  Prints a report and exits with status 2 if the files drifted or 1 on errors, e.g. to run in CI or as a login-time warning.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) >= 0 {
+			comps = cobra.AppendActiveHelp(comps, "No args expected - hit it or use -f to provide a spec file!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		var drifted bool
+		if specFile != "" {
+			drifted = checkSpec(cmd)
+		} else {
+			drifted = checkState(cmd)
+		}
+
+		if drifted {
+			os.Exit(driftExitCode)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	flags := checkCmd.Flags()
+	flags.StringVarP(&specFile, "file", "f", "", "Spec file (.yaml, .yml, .json or .toml) to compare with")
+}
+
+// checkSpec reports differences of the files to the spec and whether there are any
+func checkSpec(cmd *cobra.Command) bool {
+	spec, err := files.GetSpec(specFile)
+	if err != nil {
+		cmd.Printf("Error reading spec: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	hosts, sshConfig := readFiles(cmd)
+	if hosts == nil && len(spec.Hosts) > 0 {
+		cmd.Printf("Skipping %d hosts file entries. Use --etc-hosts to check them!\n", len(spec.Hosts))
+	}
+
+	plan, conflicts := files.PlanSpec(spec, hosts, sshConfig, true, false)
+	if plan.Empty() && conflicts == nil {
+		cmd.Printf("No drift. Files match spec '%s'.\n", spec.Name)
+
+		return false
+	}
+
+	cmd.Printf("Drift detected for spec '%s':\n", spec.Name)
+	for _, change := range plan.Hosts {
+		cmd.Print(formatDrift(change, hostsFilePath))
+	}
+	for _, change := range plan.Blocks {
+		cmd.Print(formatDrift(change, sshConfigFilePath))
+	}
+	if conflicts != nil {
+		cmd.Printf("\n%v\n", conflicts)
+	}
+
+	return true
+}
+
+// formatDrift prints change from the declared to the current entry
+func formatDrift(change *files.Change, path string) string {
+	var output strings.Builder
+
+	switch change.Action {
+	case files.Create:
+		fmt.Fprintf(&output, "\n  # %s in %s is missing\n", change.Name, path)
+	case files.Update:
+		fmt.Fprintf(&output, "\n  # %s in %s was changed\n", change.Name, path)
+	case files.Destroy:
+		fmt.Fprintf(&output, "\n  # %s in %s is not declared\n", change.Name, path)
+	}
+
+	for _, line := range diff.Lines(planLines(change.After), planLines(change.Before)) {
+		switch line.Op {
+		case diff.Insert:
+			output.WriteString("  + " + line.Text + "\n")
+		case diff.Delete:
+			output.WriteString("  - " + line.Text + "\n")
+		default:
+			output.WriteString("    " + line.Text + "\n")
+		}
+	}
+
+	return output.String()
+}
+
+// checkState reports differences of the files to their state as last written and whether there are any
+func checkState(cmd *cobra.Command) bool {
+	err := getFilePaths()
+	if err != nil {
+		cmd.Printf("Error retrieving file paths: %v", err)
+
+		os.Exit(1)
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		cmd.Printf("Error retrieving state directory: %v", err)
+
+		os.Exit(1)
+	}
+
+	paths := []string{sshConfigFilePath}
+	if etcHosts {
+		paths = []string{hostsFilePath, sshConfigFilePath}
+	}
+
+	drifted := false
+	for _, path := range paths {
+		last, found, err := files.LoadState(dir, path)
+		if err != nil {
+			cmd.Printf("Error reading state: %v\n", err)
+
+			os.Exit(1)
+		}
+		if !found {
+			cmd.Printf("No state of %s recorded yet. It is recorded whenever hosts-cli writes the file.\n", path)
+			continue
+		}
+
+		current, err := os.ReadFile(path)
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		if bytes.Equal(last, current) {
+			cmd.Printf("No drift. %s matches its state as last written by hosts-cli.\n", path)
+			continue
+		}
+
+		drifted = true
+		cmd.Printf("Drift detected. %s was changed since last written by hosts-cli:\n", path)
+		cmd.Print(diff.Unified(path+" (last written)", path, string(last), string(current), 3))
+	}
+
+	return drifted
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+// disableCmd represents the disable command
+var disableCmd = &cobra.Command{
+	Use:   "disable [HOST...]",
+	Short: "Switch off entries of one or more hosts without deleting them",
+	Long: `Switch off entries of one or more hosts in ssh-config and hosts file by commenting them out.
+  Use --tag to select all entries with one of the given tags. Use 'hosts enable' to switch them on again!
+  Entries shared with other aliases are split, so that only the given hosts are switched off.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Provide one or more host names or use --tag")
+		}
+		if len(args) > 0 {
+			comps = cobra.AppendActiveHelp(comps, "Provide more host names or hit enter")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: hostsOrTags,
+	Run: func(cmd *cobra.Command, args []string) {
+		setDisabled(cmd, args, tags, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disableCmd)
+
+	flags := disableCmd.Flags()
+	flags.BoolVarP(&force, "force", "f", false, "Include tagged entries not created by hosts-cli")
+	flags.StringSliceVarP(&tags, "tag", "t", nil, "Switch off all entries with one of the given tags; repeatable")
+}
+
+// setDisabled switches all entries of aliases and all entries tagged with one of tags off or on
+func setDisabled(cmd *cobra.Command, aliases []string, tags []string, disabled bool) {
+	validateTags(cmd, tags)
+
+	hosts, sshConfig := readFiles(cmd)
+
+	changed, skipped, err := disableEntries(hosts, sshConfig, aliases, tags, disabled, force)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+
+		os.Exit(1)
+	}
+	if skipped > 0 {
+		cmd.Printf("Skipping %d tagged entries not created by hosts-cli. Use --force to include them!\n", skipped)
+	}
+
+	if changed == 0 {
+		cmd.Println("Entries already in desired state. Nothing to do!")
+
+		return
+	}
+
+	replaceFiles(cmd, targetFiles(hosts, sshConfig)...)
+}
+
+// disableEntries switches entries off or on and returns the number of changed entries and of tagged entries
+// skipped as they were not created by hosts-cli, which are only included with force
+func disableEntries(hosts *files.Hosts, sshConfig *files.SSHConfig, aliases []string, tags []string, disabled bool, force bool) (int, int, error) {
+	changed, skipped := 0, 0
+	for _, alias := range aliases {
+		if len(sshConfig.FindBlocks(alias)) == 0 && (hosts == nil || len(hosts.FindEntries(alias)) == 0) {
+			return 0, 0, fmt.Errorf("No entries found for '%s'", alias)
+		}
+
+		if hosts != nil {
+			changed += hosts.SetDisabled(alias, disabled)
+		}
+		changed += sshConfig.SetDisabled(alias, disabled)
+	}
+
+	if len(tags) > 0 {
+		found := 0
+		if hosts != nil {
+			for _, entry := range hosts.Entries() {
+				if hasAnyTag(entry, tags) {
+					found++
+					if !force && !entry.Managed() {
+						skipped++
+					} else if entry.Disabled() != disabled {
+						entry.SetDisabled(disabled)
+						changed++
+					}
+				}
+			}
+		}
+		for _, block := range sshConfig.Blocks() {
+			if hasAnyTag(block, tags) {
+				found++
+				if !force && !block.Managed() {
+					skipped++
+				} else if block.Disabled != disabled {
+					block.Disabled = disabled
+					changed++
+				}
+			}
+		}
+
+		if found == 0 {
+			return 0, 0, fmt.Errorf("No entries found tagged %s", strings.Join(tags, ", "))
+		}
+	}
+
+	return changed, skipped, nil
+}
@@ -22,21 +22,40 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
 	"github.com/spf13/cobra"
 )
 
+const (
+	reEditAction     = "Re-edit"
+	abortAction      = "Abort and discard changes"
+	saveAnywayAction = "Save anyway"
+)
+
+var (
+	editorName string
+)
+
 // editCmd represents the edit command
 var editCmd = &cobra.Command{
-	Use:   "edit [EDITOR]",
+	Use:   "edit [ALIAS]",
 	Short: "Edit host entries of SSH config and optionally hosts file",
-	Long:  `Edit host entries of SSH config and optionally hosts file with the editor of your choice. Remember :wq to escape vim!`,
+	Long: `Edit host entries of SSH config and optionally hosts file with the editor of your choice. Remember :wq to escape vim!
+  Pass an alias to only edit the entries of a single host. Changes are validated before the original files get replaced.
+  Use --editor to pick the editor.`,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		var comps []string
 		if len(args) == 0 {
-			comps = cobra.AppendActiveHelp(comps, "Provide an editor or hit enter")
+			comps = cobra.AppendActiveHelp(comps, "Provide an alias or hit enter")
 		}
 		if len(args) == 1 {
 			comps = cobra.AppendActiveHelp(comps, "Hit it!")
@@ -48,18 +67,15 @@ var editCmd = &cobra.Command{
 	},
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		editor := os.Getenv("EDITOR")
-		if len(args) == 1 {
-
-			if _, err := exec.LookPath(args[0]); err != nil {
-				cmd.Printf("Executable '%s' not found in $PATH. Try nano or vi!\n", args[0])
+		editor := defaultEditor()
+		if editorName != "" {
+			if _, err := exec.LookPath(editorName); err != nil {
+				cmd.Printf("Executable '%s' not found in $PATH. Try nano or vi!\n", editorName)
 
 				os.Exit(1)
 			}
 
-			editor = args[0]
-		} else if editor == "" {
-			editor = "vi"
+			editor = editorName
 		}
 
 		err := getFilePaths()
@@ -69,38 +85,212 @@ var editCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if etcHosts {
-			vi := exec.Command(editor, hostsFilePath)
-			vi.Stdin = os.Stdin
-			vi.Stdout = os.Stdout
-			if err := vi.Start(); err != nil {
-				cmd.Printf("Error opening file with vi: %v", err)
+		if len(args) == 1 {
+			var hosts *files.Hosts
+			if etcHosts {
+				hosts, err = files.GetHosts(hostsFilePath)
+				if err != nil {
+					cmd.Printf("Error reading file: %v", err)
+
+					os.Exit(1)
+				}
+			}
+
+			sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+			if err != nil {
+				cmd.Printf("Error reading file: %v", err)
 
 				os.Exit(1)
 			}
-			if err := vi.Wait(); err != nil {
-				cmd.Printf("Unexpected error occurred: %v", err)
+
+			if !helpers.SliceContains(listAliases(hosts, sshConfig), args[0]) {
+				cmd.Printf("'%s' is not a known host. Use --editor to pick the editor!\n", args[0])
 
 				os.Exit(1)
 			}
-		}
 
-		vi := exec.Command(editor, sshConfigFilePath)
-		vi.Stdin = os.Stdin
-		vi.Stdout = os.Stdout
-		if err := vi.Start(); err != nil {
-			cmd.Printf("Error opening file with vi: %v", err)
+			editHost(cmd, editor, hosts, sshConfig, args[0])
 
-			os.Exit(1)
+			return
 		}
-		if err := vi.Wait(); err != nil {
-			cmd.Printf("Unexpected error occurred: %v", err)
 
-			os.Exit(1)
+		if etcHosts {
+			editFile(cmd, editor, hostsFilePath, files.ValidateHosts)
 		}
+
+		editFile(cmd, editor, sshConfigFilePath, files.ValidateSSHConfig)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(editCmd)
+
+	flags := editCmd.Flags()
+	flags.StringVarP(&editorName, "editor", "e", "", "Editor to use; default: $EDITOR or vi")
+}
+
+// editHost lets the user edit the entries of a single host and splices them back into both files
+func editHost(cmd *cobra.Command, editor string, hosts *files.Hosts, sshConfig *files.SSHConfig, alias string) {
+	original := files.ExtractSnippet(hosts, sshConfig, alias)
+
+	header := fmt.Sprintf("# Editing entries of '%s'. Delete lines to remove entries, comments are ignored.\n", alias)
+	if hosts != nil {
+		header += fmt.Sprintf("# Hosts file: %s\n", hostsFilePath)
+	}
+	header += fmt.Sprintf("# SSH config: %s\n\n", sshConfigFilePath)
+	content := []byte(header + original.String())
+
+	validate := func(content string) error {
+		_, err := files.ParseSnippet(content)
+		return err
+	}
+
+	edited, ok := editContent(cmd, editor, alias, content, validate, false)
+	if !ok {
+		cmd.Printf("Aborted. Entries of '%s' not changed!\n", alias)
+
+		return
+	}
+
+	if bytes.Equal(edited, content) {
+		cmd.Printf("No changes made to '%s'\n", alias)
+
+		return
+	}
+
+	updated, err := files.ParseSnippet(string(edited))
+	if err != nil {
+		cmd.Printf("Error parsing entries of '%s':\n%v\n", alias, err)
+
+		os.Exit(1)
+	}
+
+	if err := files.ApplySnippet(hosts, sshConfig, original, updated); err != nil {
+		cmd.Printf("Error applying changes: %v", err)
+
+		os.Exit(1)
+	}
+
+	writeFiles(cmd, hosts, sshConfig)
+}
+
+// editFile lets the user edit a temporary copy of path and replaces the original once the content is valid
+func editFile(cmd *cobra.Command, editor string, path string, validate func(string) error) {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		cmd.Printf("Error reading file: %v", err)
+
+		os.Exit(1)
+	}
+
+	edited, ok := editContent(cmd, editor, path, original, validate, true)
+	if !ok {
+		cmd.Printf("Aborted. %s not changed!\n", path)
+
+		return
+	}
+
+	if bytes.Equal(edited, original) {
+		cmd.Printf("No changes made to %s\n", path)
+
+		return
+	}
+
+	replaceFiles(cmd, &fileContent{path: path, content: edited})
+}
+
+// editContent opens content in the editor until it passes validation or the user decides otherwise. Invalid
+// content can only be saved anyway if saveAnyway is set, e.g. not for snippets which must be parsed.
+func editContent(cmd *cobra.Command, editor string, name string, content []byte, validate func(string) error, saveAnyway bool) ([]byte, bool) {
+	tmp, err := os.CreateTemp("", "hosts-*-"+filepath.Base(name))
+	if err != nil {
+		cmd.Printf("Error creating temporary file: %v", err)
+
+		os.Exit(1)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	tmp.Close()
+	if err != nil {
+		cmd.Printf("Error writing temporary file: %v", err)
+
+		os.Exit(1)
+	}
+
+	for {
+		openEditor(cmd, editor, tmp.Name())
+
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		err = validate(string(edited))
+		if err == nil {
+			return edited, true
+		}
+
+		cmd.Printf("Invalid content for %s:\n", name)
+		var parseErrors files.ParseErrors
+		if errors.As(err, &parseErrors) {
+			for _, parseError := range parseErrors {
+				cmd.Printf("  %v\n", parseError)
+			}
+		} else {
+			cmd.Printf("  %v\n", err)
+		}
+
+		if !helpers.IsTerminal() {
+			return nil, false
+		}
+
+		options := []string{reEditAction, abortAction}
+		if saveAnyway {
+			options = append(options, saveAnywayAction)
+		}
+
+		action := ""
+		prompt := &survey.Select{
+			Message: "What now?",
+			Options: options,
+		}
+		if err := survey.AskOne(prompt, &action); err != nil {
+			exitOnPromptError(cmd, err)
+		}
+
+		switch action {
+		case abortAction:
+			return nil, false
+		case saveAnywayAction:
+			return edited, true
+		}
+	}
+}
+
+func defaultEditor() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+
+	return "vi"
+}
+
+func openEditor(cmd *cobra.Command, editor string, path string) {
+	vi := exec.Command(editor, path)
+	vi.Stdin = os.Stdin
+	vi.Stdout = os.Stdout
+	vi.Stderr = os.Stderr
+	if err := vi.Start(); err != nil {
+		cmd.Printf("Error opening file with %s: %v", editor, err)
+
+		os.Exit(1)
+	}
+	if err := vi.Wait(); err != nil {
+		cmd.Printf("Unexpected error occurred: %v", err)
+
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,56 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// enableCmd represents the enable command
+var enableCmd = &cobra.Command{
+	Use:   "enable [HOST...]",
+	Short: "Switch on previously disabled entries of one or more hosts",
+	Long: `Switch on entries of one or more hosts in ssh-config and hosts file that were disabled by 'hosts disable'.
+  Use --tag to select all entries with one of the given tags.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Provide one or more host names or use --tag")
+		}
+		if len(args) > 0 {
+			comps = cobra.AppendActiveHelp(comps, "Provide more host names or hit enter")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: hostsOrTags,
+	Run: func(cmd *cobra.Command, args []string) {
+		setDisabled(cmd, args, tags, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(enableCmd)
+
+	flags := enableCmd.Flags()
+	flags.BoolVarP(&force, "force", "f", false, "Include tagged entries not created by hosts-cli")
+	flags.StringSliceVarP(&tags, "tag", "t", nil, "Switch on all entries with one of the given tags; repeatable")
+}
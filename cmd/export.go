@@ -0,0 +1,118 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [HOST...]",
+	Short: "Export host entries of ssh-config and hosts file",
+	Long: `Export host entries of ssh-config and hosts file to stdout or a file (--output).
+  Select entries by host names and/or --tag, or export all entries if neither is given.
+  The output can be used with 'hosts profile create --from-file'.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Hit enter to export all entries, provide one or more host names or use --tag")
+		}
+		if len(args) > 0 {
+			comps = cobra.AppendActiveHelp(comps, "Provide more host names or hit enter")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		validateTags(cmd, tags)
+
+		hosts, sshConfig := readFiles(cmd)
+
+		snippet := &files.Snippet{Blocks: []*files.HostBlock{}}
+		if hosts != nil {
+			snippet.Hosts = []*files.Host{}
+			for _, entry := range hosts.Entries() {
+				if selectedForExport(entry.Aliases(), entry, args) {
+					snippet.Hosts = append(snippet.Hosts, entry)
+				}
+			}
+		}
+		for _, block := range sshConfig.Blocks() {
+			if block.Kind != "" && selectedForExport(block.Hosts, block, args) {
+				snippet.Blocks = append(snippet.Blocks, block)
+			}
+		}
+
+		if len(snippet.Hosts) == 0 && len(snippet.Blocks) == 0 {
+			cmd.Println("No matching entries found. Nothing to export!")
+
+			os.Exit(1)
+		}
+
+		if exportOutput == "" {
+			fmt.Fprint(cmd.OutOrStdout(), snippet.String())
+
+			return
+		}
+
+		if err := os.WriteFile(exportOutput, []byte(snippet.String()), 0644); err != nil {
+			cmd.Printf("Error writing file %s: %v", exportOutput, err)
+
+			os.Exit(1)
+		}
+
+		cmd.Printf("Exported %d hosts file entries and %d ssh config blocks to %s\n", len(snippet.Hosts), len(snippet.Blocks), exportOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	flags := exportCmd.Flags()
+	flags.StringSliceVarP(&tags, "tag", "t", nil, "Export all entries with one of the given tags; repeatable")
+	flags.StringVarP(&exportOutput, "output", "o", "", "Write to file instead of stdout")
+}
+
+// selectedForExport reports whether an entry mapping aliases matches the selection of args and --tag
+func selectedForExport(aliases []string, entry tagged, args []string) bool {
+	if len(args) == 0 && len(tags) == 0 {
+		return true
+	}
+
+	for _, arg := range args {
+		if helpers.SliceContains(aliases, arg) {
+			return true
+		}
+	}
+
+	return hasAnyTag(entry, tags)
+}
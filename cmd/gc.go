@@ -0,0 +1,117 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+// gcCmd represents the gc command
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove expired entries added with 'hosts add --ttl'",
+	Long: `Remove entries from ssh-config and hosts file whose TTL set by 'hosts add --ttl' has expired.
+  Entries shared by owners with 'hosts add --owner' are released by each owner whose TTL has expired and
+  removed once no owner is left. Entries not created by hosts-cli are skipped unless --force is given.
+  Suitable for cron jobs and systemd timers, e.g. 'sudo hosts gc --etc-hosts'.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) >= 0 {
+			comps = cobra.AppendActiveHelp(comps, "No args expected - hit it!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		hosts, sshConfig := readFiles(cmd)
+
+		now := time.Now()
+		changed, skipped := 0, 0
+		expired := func(entry expiringEntry, name string, path string) bool {
+			released := entry.ReleaseExpiredOwners(now)
+			for _, owner := range released {
+				cmd.Printf("Released '%s' of expired owner '%s' in %s\n", name, owner, path)
+				changed++
+			}
+			if len(entry.Owners()) > 0 {
+				return false
+			}
+
+			_, hasExpires := entry.Expires()
+			if !entry.Expired(now) && (hasExpires || len(released) == 0) {
+				return false
+			}
+			if !force && !entry.Managed() {
+				skipped++
+
+				return false
+			}
+
+			return true
+		}
+
+		if hosts != nil {
+			for _, entry := range hosts.RemoveEntriesFunc(func(host *files.Host) bool {
+				return expired(host, strings.Join(host.Aliases(), " "), hostsFilePath)
+			}) {
+				cmd.Printf("Removed expired entry '%s %s' from %s\n", entry.Address(), strings.Join(entry.Aliases(), " "), hostsFilePath)
+				changed++
+			}
+		}
+		for _, block := range sshConfig.RemoveBlocksFunc(func(block *files.HostBlock) bool {
+			return expired(block, strings.Join(block.Hosts, " "), sshConfigFilePath)
+		}) {
+			cmd.Printf("Removed expired block '%s %s' from %s\n", block.Kind, strings.Join(block.Hosts, " "), sshConfigFilePath)
+			changed++
+		}
+
+		if skipped > 0 {
+			cmd.Printf("Skipping %d expired entries not created by hosts-cli. Use --force to remove them!\n", skipped)
+		}
+
+		if changed == 0 {
+			return
+		}
+
+		replaceFiles(cmd, targetFiles(hosts, sshConfig)...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	flags := gcCmd.Flags()
+	flags.BoolVarP(&force, "force", "f", false, "Also remove expired entries not created by hosts-cli")
+}
+
+// expiringEntry is implemented by hosts file entries and ssh config blocks
+type expiringEntry interface {
+	managedEntry
+	Owners() []string
+	ReleaseExpiredOwners(now time.Time) []string
+	Expires() (time.Time, bool)
+	Expired(now time.Time) bool
+}
@@ -0,0 +1,213 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create entries from other sources, e.g. known_hosts",
+	Long:  `Create hosts file entries and ssh config Host blocks from other sources, e.g. 'hosts import known-hosts'.`,
+	Args:  cobra.ExactArgs(0),
+}
+
+var importKnownHostsCmd = &cobra.Command{
+	Use:   "known-hosts [FILE...]",
+	Short: "Create entries for hosts recorded with their IP address in known_hosts",
+	Long: `Create hosts file entries and ssh config Host blocks for all unhashed host names recorded together with their
+  IP address in known_hosts (see CheckHostIP in ssh_config), e.g. 'web01,10.0.0.5 ssh-ed25519 AAAA...'.
+  Hosts which already have a Host block are skipped, existing hosts file entries are kept. Hosts recorded more
+  than once, e.g. with an old and a new IP address, are imported with the last record.
+  Reads ~/.ssh/known_hosts unless files are given. Asks which hosts to import in a terminal unless --auto-approve is given.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		comps = cobra.AppendActiveHelp(comps, "Expecting known_hosts files or enter key for ~/.ssh/known_hosts")
+		return comps, cobra.ShellCompDirectiveDefault
+	},
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		validateTags(cmd, tags)
+
+		hosts, sshConfig := readFiles(cmd)
+
+		if len(args) == 0 {
+			args = []string{knownHostsFilePath}
+		}
+
+		addresses := make([]*files.KnownHostAddress, 0, 10)
+		for _, path := range args {
+			knownHosts, err := files.GetKnownHosts(path)
+			if err != nil {
+				cmd.Printf("Error reading file: %v", err)
+
+				os.Exit(1)
+			}
+
+			addresses = append(addresses, knownHosts.Addresses()...)
+		}
+
+		candidates, existing, superseded := knownHostsCandidates(sshConfig, addresses)
+		if existing > 0 {
+			cmd.Printf("Skipping %d hosts which already exist\n", existing)
+		}
+		if superseded > 0 {
+			cmd.Printf("Skipping %d older records of hosts recorded again later\n", superseded)
+		}
+		if len(candidates) == 0 {
+			cmd.Println("No hosts to import found. Nothing to do!")
+
+			return
+		}
+
+		if !autoApprove && helpers.IsTerminal() {
+			var err error
+			candidates, err = selectKnownHosts(candidates)
+			if err != nil {
+				exitOnPromptError(cmd, err)
+			}
+			if len(candidates) == 0 {
+				cmd.Println("No hosts selected. Nothing to do!")
+
+				return
+			}
+		}
+
+		for _, address := range candidates {
+			opts := &addOptions{Tags: tags}
+			if address.Port != "22" {
+				opts.Port = address.Port
+			}
+
+			entryHosts := hosts
+			if hosts != nil && len(findAliases(hosts, address.Aliases)) > 0 {
+				entryHosts = nil // only the Host block is missing
+			}
+			if err := addEntries(cmd, entryHosts, sshConfig, address.Address, address.Aliases, opts); err != nil {
+				cmd.Printf("%v\n", err)
+
+				os.Exit(1)
+			}
+		}
+
+		writeFiles(cmd, hosts, sshConfig)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importKnownHostsCmd)
+
+	flags := importKnownHostsCmd.Flags()
+	flags.StringSliceVarP(&tags, "tag", "t", nil, "Tag imported entries; repeatable")
+	flags.BoolVar(&autoApprove, "auto-approve", false, "Import all hosts without asking")
+}
+
+// knownHostsCandidates returns the addresses to import and the number of addresses skipped as their hosts have
+// a Host block already or are recorded again later. Hosts recorded more than once, e.g. after the server got a
+// new IP address, are imported with their last record, as ssh appends new records at the end.
+func knownHostsCandidates(sshConfig *files.SSHConfig, addresses []*files.KnownHostAddress) ([]*files.KnownHostAddress, int, int) {
+	candidates := make([]*files.KnownHostAddress, 0, len(addresses))
+	existing, superseded := 0, 0
+	for i := len(addresses) - 1; i >= 0; i-- {
+		switch {
+		case hasHostBlock(sshConfig, addresses[i].Aliases):
+			existing++
+		case isCandidate(candidates, addresses[i].Aliases):
+			superseded++
+		default:
+			candidates = append(candidates, addresses[i])
+		}
+	}
+
+	for i, j := 0, len(candidates)-1; i < j; i, j = i+1, j-1 {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	}
+
+	return candidates, existing, superseded
+}
+
+func hasHostBlock(sshConfig *files.SSHConfig, aliases []string) bool {
+	for _, alias := range aliases {
+		if len(sshConfig.FindBlocks(alias)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isCandidate(candidates []*files.KnownHostAddress, aliases []string) bool {
+	for _, alias := range aliases {
+		for _, candidate := range candidates {
+			if helpers.SliceContains(candidate.Aliases, alias) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// findAliases returns the entries of hosts mapping one of aliases
+func findAliases(hosts *files.Hosts, aliases []string) []*files.Host {
+	found := make([]*files.Host, 0)
+	for _, alias := range aliases {
+		found = append(found, hosts.FindEntries(alias)...)
+	}
+
+	return found
+}
+
+func selectKnownHosts(candidates []*files.KnownHostAddress) ([]*files.KnownHostAddress, error) {
+	options := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		options[i] = fmt.Sprintf("%s (%s)", strings.Join(candidate.Aliases, " "), files.KnownHostsName(candidate.Address, candidate.Port))
+	}
+
+	selected := []int{}
+	prompt := &survey.MultiSelect{
+		Message:  "Select hosts to import (type to filter):",
+		Options:  options,
+		Default:  options,
+		PageSize: 15,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return nil, err
+	}
+
+	result := make([]*files.KnownHostAddress, len(selected))
+	for i, index := range selected {
+		result[i] = candidates[index]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+var (
+	onlyDisabled bool
+)
+
+// lsCmd represents the ls command
+var lsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List host entries of ssh-config and hosts file",
+	Long: `List host entries of ssh-config and hosts file including disabled ones.
+  Use --tag to only list entries carrying one of the given tags.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) >= 0 {
+			comps = cobra.AppendActiveHelp(comps, "No args expected - hit it!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		validateTags(cmd, tags)
+
+		err := getFilePaths()
+		if err != nil {
+			cmd.Printf("Error retrieving file paths: %v", err)
+
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "STATE\tADDRESS\tALIASES\tTAGS\tSOURCE")
+
+		if etcHosts {
+			hosts, err := files.GetHosts(hostsFilePath)
+			if err != nil {
+				cmd.Printf("Error reading file: %v", err)
+
+				os.Exit(1)
+			}
+
+			for _, entry := range hosts.Entries() {
+				if (onlyDisabled && !entry.Disabled()) || (len(tags) > 0 && !hasAnyTag(entry, tags)) {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", state(entry.Disabled()), entry.Address(), strings.Join(entry.Aliases(), " "), strings.Join(entry.Tags(), ","), hostsFilePath)
+			}
+		}
+
+		sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		for _, block := range sshConfig.Blocks() {
+			if strings.ToUpper(block.Kind) != "HOST" || (onlyDisabled && !block.Disabled) || (len(tags) > 0 && !hasAnyTag(block, tags)) {
+				continue
+			}
+			hostname, _ := block.GetProp("HostName")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", state(block.Disabled), hostname, strings.Join(block.Hosts, " "), strings.Join(block.Tags(), ","), sshConfigFilePath)
+		}
+
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+
+	flags := lsCmd.Flags()
+	flags.BoolVar(&onlyDisabled, "disabled", false, "Only list disabled entries")
+	flags.StringSliceVarP(&tags, "tag", "t", nil, "Only list entries with one of the given tags; repeatable")
+}
+
+func state(disabled bool) string {
+	if disabled {
+		return "disabled"
+	}
+
+	return "enabled"
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameKnownHosts bool
+)
+
+// mvCmd represents the mv command
+var mvCmd = &cobra.Command{
+	Use:   "mv OLD NEW",
+	Short: "Rename an alias in ssh-config, hosts file and optionally known_hosts",
+	Long: `Rename an alias in all Host blocks of ssh-config, the hosts file and optionally known_hosts.
+  Either all files get updated or none! Aliases of the localhost or broadcasthost entries are only renamed
+  with --force.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting current host name")
+		} else if len(args) == 1 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting new host name")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Too many arguments specified!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		oldAlias, newAlias := args[0], args[1]
+		if !helpers.IsValidHostname(newAlias) {
+			cmd.Printf("'%s' is not a valid host name\n", newAlias)
+
+			os.Exit(1)
+		}
+
+		err := getFilePaths()
+		if err != nil {
+			cmd.Printf("Error retrieving file paths: %v", err)
+
+			os.Exit(1)
+		}
+
+		var hosts *files.Hosts
+		if etcHosts {
+			hosts, err = files.GetHosts(hostsFilePath)
+			if err != nil {
+				cmd.Printf("Error reading file: %v", err)
+
+				os.Exit(1)
+			}
+		}
+
+		sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		aliases := listAliases(hosts, sshConfig)
+		if !helpers.SliceContains(aliases, oldAlias) {
+			cmd.Printf("No entries found for '%s'\n", oldAlias)
+
+			os.Exit(1)
+		}
+		if helpers.SliceContains(aliases, newAlias) {
+			cmd.Printf("'%s' is already in use. Remove it first!\n", newAlias)
+
+			os.Exit(1)
+		}
+
+		if hosts != nil && !force {
+			for _, entry := range hosts.FindEntries(oldAlias) {
+				if entry.Required() {
+					cmd.Printf("'%s' belongs to the required entry '%s %s'. Use --force to rename it anyway!\n", oldAlias, entry.Address(), strings.Join(entry.Aliases(), " "))
+
+					os.Exit(1)
+				}
+			}
+		}
+
+		port := ""
+		if blocks := sshConfig.FindBlocks(oldAlias); len(blocks) > 0 {
+			port, _ = blocks[0].GetProp("Port")
+		}
+
+		targets := make([]file, 0, 3)
+		if hosts != nil {
+			hosts.RenameAlias(oldAlias, newAlias)
+			targets = append(targets, hosts)
+		}
+		sshConfig.RenameHost(oldAlias, newAlias)
+		targets = append(targets, sshConfig)
+
+		if renameKnownHosts {
+			knownHosts, err := files.GetKnownHosts(knownHostsFilePath)
+			if err != nil {
+				cmd.Printf("Error reading file: %v", err)
+
+				os.Exit(1)
+			}
+
+			renamed, err := knownHosts.RenameHost(oldAlias, newAlias)
+			if err == nil && port != "" {
+				var n int
+				n, err = knownHosts.RenameHost(files.KnownHostsName(oldAlias, port), files.KnownHostsName(newAlias, port))
+				renamed += n
+			}
+			if err != nil {
+				cmd.Printf("Error renaming host in %s: %v", knownHostsFilePath, err)
+
+				os.Exit(1)
+			}
+
+			if renamed > 0 {
+				targets = append(targets, knownHosts)
+			}
+		}
+
+		replaceFiles(cmd, targets...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+
+	flags := mvCmd.Flags()
+	flags.BoolVar(&renameKnownHosts, "known-hosts", false, "Also rename the host in known_hosts, including hashed entries")
+	flags.BoolVarP(&force, "force", "f", false, "Also rename aliases of the localhost or broadcasthost entries")
+}
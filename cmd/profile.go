@@ -0,0 +1,476 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+const profileMetaKey = "profile"
+
+var (
+	profilesDir      string
+	profileFromFile  string
+	overwriteProfile bool
+	swapProfiles     bool
+
+	defaultProfilesDir bool
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named sets of entries that can be switched on and off as a unit",
+	Long: `Manage named sets of hosts file entries and ssh config blocks, e.g. staging or local-dev.
+  Profiles are stored by the CLI and written to ssh-config and hosts file when activated with 'hosts profile use'.`,
+	Args: cobra.ExactArgs(0),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if profilesDir != "" {
+			return
+		}
+
+		configDir, err := configDir()
+		if err != nil {
+			cmd.Printf("Error retrieving user's config directory: %v", err)
+
+			os.Exit(1)
+		}
+		profilesDir = filepath.Join(configDir, "hosts-cli", "profiles")
+		defaultProfilesDir = true
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create NAME [ALIAS...]",
+	Short: "Create a profile from existing entries, a file or in your editor",
+	Long: `Create a profile from the current entries of the given aliases, from a file (--from-file) or,
+  if neither is given, by writing it in your editor. Copied entries are marked as the active profile and
+  stored without their tags, owners and expiry.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting profile name")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Expecting host names to copy entries from or enter key")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if !files.IsValidProfileName(name) {
+			cmd.Printf("'%s' is not a valid profile name. Use letters, digits, '.', '_' and '-'!\n", name)
+
+			os.Exit(1)
+		}
+
+		path := files.ProfilePath(profilesDir, name)
+		if _, err := os.Stat(path); err == nil && !overwriteProfile {
+			cmd.Printf("Profile '%s' already exists. Use --force to overwrite it!\n", name)
+
+			os.Exit(1)
+		}
+
+		var snippet *files.Snippet
+		var hosts *files.Hosts
+		var sshConfig *files.SSHConfig
+		switch {
+		case profileFromFile != "":
+			content, err := os.ReadFile(profileFromFile)
+			if err != nil {
+				cmd.Printf("Error reading file: %v", err)
+
+				os.Exit(1)
+			}
+
+			snippet, err = files.ParseSnippet(string(content))
+			if err != nil {
+				cmd.Printf("Invalid content in %s:\n%v\n", profileFromFile, err)
+
+				os.Exit(1)
+			}
+
+		case len(args) > 1:
+			hosts, sshConfig = readFiles(cmd)
+			snippet = snippetOfAliases(cmd, hosts, sshConfig, name, args[1:])
+
+		default:
+			if !helpers.IsTerminal() {
+				cmd.Println("Provide host names or --from-file when not running in a terminal!")
+
+				os.Exit(1)
+			}
+
+			template := fmt.Sprintf("# Profile '%s'. Add hosts file entries and ssh config blocks below, comments are ignored.\n\n", name)
+			template += (&files.Snippet{Hosts: []*files.Host{}, Blocks: []*files.HostBlock{}}).String()
+
+			validate := func(content string) error {
+				_, err := files.ParseSnippet(content)
+				return err
+			}
+
+			content, ok := editContent(cmd, defaultEditor(), name, []byte(template), validate, false)
+			if !ok {
+				cmd.Println("Aborted. Profile not created!")
+
+				return
+			}
+
+			snippet, _ = files.ParseSnippet(string(content))
+		}
+
+		for _, host := range snippet.Hosts {
+			host.ClearMeta()
+		}
+		for _, block := range snippet.Blocks {
+			block.ClearMeta()
+		}
+
+		profile := files.NewProfile(profilesDir, name, snippet)
+		if err := profile.Write(); err != nil {
+			cmd.Printf("Error writing profile: %v", err)
+
+			os.Exit(1)
+		}
+		if err := chownProfile(profile); err != nil {
+			cmd.Printf("Warning: failed handing profile to %s: %v\n", os.Getenv("SUDO_USER"), err)
+		}
+
+		cmd.Printf("Created profile '%s' with %d hosts file entries and %d ssh config blocks\n", name, len(snippet.Hosts), len(snippet.Blocks))
+
+		if sshConfig != nil {
+			// the copied entries are the profile now, so that 'hosts profile use' and 'off' replace them
+			markProfile(snippet, name)
+			replaceFiles(cmd, targetFiles(hosts, sshConfig)...)
+		}
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List all profiles and whether they are active",
+	Args:    cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		names, err := files.ListProfiles(profilesDir)
+		if err != nil {
+			cmd.Printf("Error listing profiles: %v", err)
+
+			os.Exit(1)
+		}
+
+		hosts, sshConfig := readFiles(cmd)
+		active := activeProfiles(hosts, sshConfig)
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSTATE\tALIASES")
+		for _, name := range names {
+			profile, err := files.GetProfile(profilesDir, name)
+			if err != nil {
+				fmt.Fprintf(w, "%s\t%s\t%v\n", name, "invalid", strings.ReplaceAll(err.Error(), "\n", " "))
+				continue
+			}
+
+			state := "inactive"
+			if helpers.SliceContains(active, name) {
+				state = "active"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, state, strings.Join(profile.Snippet.Aliases(), " "))
+		}
+		w.Flush()
+	},
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show NAME",
+	Short: "Print the entries of a profile",
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting profile name")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Too many arguments specified!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		profile := loadProfile(cmd, args[0])
+
+		cmd.Print(helpers.PrintFile(profile.Filepath(), profile))
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Activate a profile by writing its entries to ssh-config and hosts file",
+	Long: `Activate a profile by writing its entries to ssh-config and hosts file.
+  Profiles mapping the same aliases as an active profile are rejected unless --swap is given.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting profile name")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Too many arguments specified!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		profile := loadProfile(cmd, name)
+
+		hosts, sshConfig := readFiles(cmd)
+		if hosts == nil && len(profile.Snippet.Hosts) > 0 {
+			cmd.Printf("Skipping %d hosts file entries. Use --etc-hosts to apply them!\n", len(profile.Snippet.Hosts))
+		}
+
+		conflicts := map[string][]string{} // owning profile -> aliases, "" for entries without profile; disabled entries never conflict
+		for _, alias := range profile.Snippet.Aliases() {
+			owners := make([]string, 0, 2)
+			if hosts != nil {
+				for _, entry := range hosts.FindEntries(alias) {
+					if !entry.Disabled() {
+						owners = append(owners, entry.GetMeta(profileMetaKey))
+					}
+				}
+			}
+			for _, block := range sshConfig.FindBlocks(alias) {
+				if !block.Disabled {
+					owners = append(owners, block.GetMeta(profileMetaKey))
+				}
+			}
+
+			for _, owner := range helpers.UniqueStrings(owners) {
+				if owner != name {
+					conflicts[owner] = append(conflicts[owner], alias)
+				}
+			}
+		}
+
+		if aliases, ok := conflicts[""]; ok {
+			cmd.Printf("Aliases %s are already mapped outside of profiles. Disable or remove them first!\n", strings.Join(aliases, ", "))
+
+			os.Exit(1)
+		}
+
+		others := make([]string, 0, len(conflicts))
+		for other := range conflicts {
+			others = append(others, other)
+		}
+		sort.Strings(others)
+
+		if len(others) > 0 && !swapProfiles {
+			for _, other := range others {
+				cmd.Printf("Profile '%s' conflicts with active profile '%s' on %s\n", name, other, strings.Join(conflicts[other], ", "))
+			}
+			cmd.Println("Use --swap to deactivate conflicting profiles!")
+
+			os.Exit(1)
+		}
+
+		for _, other := range others {
+			deactivateProfile(hosts, sshConfig, other)
+			cmd.Printf("Deactivated profile '%s'\n", other)
+		}
+
+		deactivateProfile(hosts, sshConfig, name) // re-activating replaces previous entries
+		markProfile(profile.Snippet, name)
+		if hosts != nil {
+			hosts.ReplaceEntries(nil, profile.Snippet.Hosts)
+		}
+		sshConfig.ReplaceBlocks(nil, profile.Snippet.Blocks)
+
+		replaceFiles(cmd, targetFiles(hosts, sshConfig)...)
+	},
+}
+
+var profileOffCmd = &cobra.Command{
+	Use:   "off NAME",
+	Short: "Deactivate a profile by removing its entries from ssh-config and hosts file",
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting profile name")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Too many arguments specified!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		hosts, sshConfig := readFiles(cmd)
+
+		if deactivateProfile(hosts, sshConfig, args[0]) == 0 {
+			cmd.Printf("Profile '%s' is not active. Nothing to do!\n", args[0])
+
+			return
+		}
+
+		replaceFiles(cmd, targetFiles(hosts, sshConfig)...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileCreateCmd, profileListCmd, profileShowCmd, profileUseCmd, profileOffCmd)
+
+	profileCmd.PersistentFlags().StringVar(&profilesDir, "profiles-dir", "", "Set directory of stored profiles; default: <user config dir>/hosts-cli/profiles")
+
+	createFlags := profileCreateCmd.Flags()
+	createFlags.StringVarP(&profileFromFile, "from-file", "f", "", "Read entries from file in the format of 'hosts profile show'")
+	createFlags.BoolVar(&overwriteProfile, "force", false, "Overwrite existing profile")
+
+	profileUseCmd.Flags().BoolVar(&swapProfiles, "swap", false, "Deactivate active profiles mapping the same aliases")
+}
+
+// chownProfile hands profile to the invoking user under sudo, with the directories created for it in the
+// default location
+func chownProfile(profile *files.Profile) error {
+	names := []string{profile.Filepath()}
+	if defaultProfilesDir {
+		names = []string{filepath.Dir(filepath.Dir(profilesDir)), filepath.Dir(profilesDir), profilesDir, profile.Filepath()}
+	}
+
+	return chownSudoUser(names...)
+}
+
+func loadProfile(cmd *cobra.Command, name string) *files.Profile {
+	profile, err := files.GetProfile(profilesDir, name)
+	if err != nil {
+		cmd.Printf("Error reading profile: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	return profile
+}
+
+// snippetOfAliases collects all current entries of aliases for the profile name. Entries of other profiles
+// are rejected.
+func snippetOfAliases(cmd *cobra.Command, hosts *files.Hosts, sshConfig *files.SSHConfig, name string, aliases []string) *files.Snippet {
+	snippet := &files.Snippet{Blocks: []*files.HostBlock{}}
+	if hosts != nil {
+		snippet.Hosts = []*files.Host{}
+	}
+
+	for _, alias := range aliases {
+		found := files.ExtractSnippet(hosts, sshConfig, alias)
+		if len(found.Hosts) == 0 && len(found.Blocks) == 0 {
+			cmd.Printf("No entries found for '%s'\n", alias)
+
+			os.Exit(1)
+		}
+
+		for _, host := range found.Hosts {
+			if host.Required() {
+				cmd.Printf("'%s' maps %s, which must stay outside of profiles!\n", alias, strings.Join(host.Aliases(), ", "))
+
+				os.Exit(1)
+			}
+			exitOnOtherProfile(cmd, alias, host.GetMeta(profileMetaKey), name)
+			if !containsEntry(snippet.Hosts, host) {
+				snippet.Hosts = append(snippet.Hosts, host)
+			}
+		}
+		for _, block := range found.Blocks {
+			exitOnOtherProfile(cmd, alias, block.GetMeta(profileMetaKey), name)
+			if !containsEntry(snippet.Blocks, block) {
+				snippet.Blocks = append(snippet.Blocks, block)
+			}
+		}
+	}
+
+	return snippet
+}
+
+func exitOnOtherProfile(cmd *cobra.Command, alias string, owner string, name string) {
+	if owner != "" && owner != name {
+		cmd.Printf("Entries of '%s' already belong to profile '%s'!\n", alias, owner)
+
+		os.Exit(1)
+	}
+}
+
+func containsEntry[T comparable](entries []T, entry T) bool {
+	for _, e := range entries {
+		if e == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+func activeProfiles(hosts *files.Hosts, sshConfig *files.SSHConfig) []string {
+	active := make([]string, 0, 5)
+	if hosts != nil {
+		for _, entry := range hosts.Entries() {
+			if name := entry.GetMeta(profileMetaKey); name != "" {
+				active = append(active, name)
+			}
+		}
+	}
+	for _, block := range sshConfig.Blocks() {
+		if name := block.GetMeta(profileMetaKey); name != "" {
+			active = append(active, name)
+		}
+	}
+
+	return helpers.UniqueStrings(active)
+}
+
+// markProfile marks all entries of snippet as managed entries of the profile name
+func markProfile(snippet *files.Snippet, name string) {
+	for _, host := range snippet.Hosts {
+		host.SetMeta(profileMetaKey, name)
+		host.SetManaged(true)
+	}
+	for _, block := range snippet.Blocks {
+		block.SetMeta(profileMetaKey, name)
+		block.SetManaged(true)
+	}
+}
+
+// deactivateProfile removes all entries of the profile and returns their number
+func deactivateProfile(hosts *files.Hosts, sshConfig *files.SSHConfig, name string) int {
+	removed := 0
+	if hosts != nil {
+		removed += len(hosts.RemoveEntriesFunc(func(host *files.Host) bool {
+			return host.GetMeta(profileMetaKey) == name
+		}))
+	}
+	removed += len(sshConfig.RemoveBlocksFunc(func(block *files.HostBlock) bool {
+		return block.GetMeta(profileMetaKey) == name
+	}))
+
+	return removed
+}
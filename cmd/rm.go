@@ -22,9 +22,14 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/martinnirtl/hosts-cli/internal/helpers"
 	"github.com/martinnirtl/hosts-cli/pkg/files"
 	"github.com/spf13/cobra"
@@ -32,96 +37,292 @@ import (
 
 var (
 	interactiveMode bool
+	force           bool
+	cleanKnownHosts bool
 )
 
-// TODO add interactive mode (using survey lib) if no args provided
 // rmCmd represents the rm command
 var rmCmd = &cobra.Command{
-	Use:   "rm HOST...",
+	Use:   "rm [HOST...]",
 	Short: "Remove one or more host entries from ssh-config and hosts file",
-	Long:  `Remove one or more host entries from ssh-config and hosts file. Gonna keep those files clean!`,
+	Long: `Remove one or more host entries from ssh-config and hosts file. Gonna keep those files clean!
+  Use --tag to remove all entries with one of the given tags.
+  Entries not created by hosts-cli (e.g. written by hand or other tools) are skipped unless --force is given.
+  Use --owner to release entries shared via 'hosts add --owner'; they are removed once the last owner is gone.
+  Use --known-hosts to also remove the keys of the removed hosts, e.g. of a re-provisioned VM.
+  Run without host names and tags (or with -i) to select the entries interactively.`,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		var comps []string
 		if len(args) == 0 {
-			comps = cobra.AppendActiveHelp(comps, "Provide one or more host names")
-			// comps = cobra.AppendActiveHelp(comps, "Hit enter for interactive mode or provide one or more host names")
+			comps = cobra.AppendActiveHelp(comps, "Hit enter for interactive mode, provide one or more host names or use --tag")
 		}
 		if len(args) > 0 {
 			comps = cobra.AppendActiveHelp(comps, "Provide more host names or hit enter")
 		}
 		return comps, cobra.ShellCompDirectiveNoFileComp
 	},
-	Args: cobra.MinimumNArgs(1),
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		err := getFilePaths()
-		if err != nil {
-			cmd.Printf("Error retrieving file paths: %v", err)
+		interactive := interactiveMode || (len(args) == 0 && len(tags) == 0 && owner == "")
+		if interactive && !helpers.IsTerminal() {
+			cmd.Println("Interactive mode requires a terminal. Provide one or more host names!")
 
 			os.Exit(1)
 		}
 
-		if interactiveMode {
+		validateTags(cmd, tags)
+		validateOwner(cmd, owner)
+
+		hosts, sshConfig := readFiles(cmd)
+
+		if interactive {
+			var err error
+			args, err = selectHosts(listAliases(hosts, sshConfig), append(args, taggedAliases(hosts, sshConfig, tags)...))
+			if err != nil {
+				exitOnPromptError(cmd, err)
+			}
+			if len(args) == 0 {
+				cmd.Println("No host entries selected. Nothing to do!")
+
+				return
+			}
+		}
+
+		selectedTags := tags
+		if interactive {
+			selectedTags = nil // only preselected in interactive mode
+		}
+
+		removed := removeEntries(hosts, sshConfig, args, selectedTags, force, owner)
+		for _, skipped := range removed.skipped {
+			cmd.Printf("Skipping %s, which was not created by hosts-cli. Use --force to remove it!\n", skipped)
+		}
+		if removed.released > 0 {
+			cmd.Printf("Keeping %d entries still used by other owners\n", removed.released)
+		}
+		if len(removed.hosts) == 0 && len(removed.blocks) == 0 && removed.released == 0 {
+			cmd.Println("No matching entries found. Nothing to do!")
 
 			return
 		}
 
-		if etcHosts {
-			if hostsFilePath == "" {
-				hostsFilePath = "/etc/hosts"
+		if interactive && !dryRun {
+			cmd.Print(previewRemoval(removed.hosts, removed.blocks))
+
+			confirmed := false
+			if err := survey.AskOne(&survey.Confirm{Message: "Apply changes?"}, &confirmed); err != nil {
+				exitOnPromptError(cmd, err)
 			}
-			hosts, err := files.GetHosts(hostsFilePath)
-			if err != nil {
-				cmd.Printf("Error reading file: %v", err)
+			if !confirmed {
+				cmd.Println("Aborted. No files changed!")
 
-				os.Exit(1)
+				return
 			}
+		}
+
+		targets := targetFiles(hosts, sshConfig)
+		if cleanKnownHosts {
+			targets = append(targets, forgetKnownHosts(cmd, staleKnownHostsNames(hosts, sshConfig, removed))...)
+		}
+
+		replaceFiles(cmd, targets...)
+	},
+}
 
-			hosts.RemoveHosts(args)
+func init() {
+	rootCmd.AddCommand(rmCmd)
 
-			if !dryRun {
-				if err := hosts.Write(); err != nil {
-					cmd.Printf("Error writing file %s: %v", hostsFilePath, err)
+	flags := rmCmd.Flags()
+	flags.BoolVarP(&interactiveMode, "interactive", "i", false, "Interactively select host entries to remove")
+	flags.StringSliceVarP(&tags, "tag", "t", nil, "Remove all entries with one of the given tags; preselects them in interactive mode; repeatable")
+	flags.BoolVarP(&force, "force", "f", false, "Also remove entries not created by hosts-cli")
+	flags.StringVar(&owner, "owner", "", "Release entries of owner, all of them if no host names or tags are given")
+	flags.BoolVar(&cleanKnownHosts, "known-hosts", false, "Also remove the keys of removed aliases and addresses from known_hosts, including hashed entries")
+}
 
-					os.Exit(1)
+func listAliases(hosts *files.Hosts, sshConfig *files.SSHConfig) []string {
+	aliases := make([]string, 0, 10)
+	if hosts != nil {
+		for _, entry := range hosts.ListHosts() {
+			aliases = append(aliases, entry...)
+		}
+	}
+	if sshConfig != nil {
+		for _, entry := range sshConfig.ListHosts() {
+			for _, alias := range entry {
+				if strings.ContainsAny(alias, "*?!") {
+					continue // skip patterns like * or !bastion
 				}
+				aliases = append(aliases, alias)
 			}
+		}
+	}
+
+	aliases = helpers.UniqueStrings(aliases)
+	sort.Strings(aliases)
+
+	return aliases
+}
+
+// removal holds the result of removeEntries
+type removal struct {
+	hosts    []*files.Host
+	blocks   []*files.HostBlock
+	skipped  []string // entries not created by hosts-cli, e.g. 'web01' in /etc/hosts
+	released int      // entries kept for remaining owners
+}
+
+// removeEntries removes all entries mapping one of aliases or carrying one of tags. Entries not created by
+// hosts-cli are kept unless force is given and entries mapping localhost or broadcasthost are always kept.
+// With an owner only entries of that owner are released and removed once no other owner is left.
+func removeEntries(hosts *files.Hosts, sshConfig *files.SSHConfig, aliases []string, tags []string, force bool, owner string) *removal {
+	result := &removal{}
+	selected := func(path string, entryAliases []string, entry removableEntry) bool {
+		matches := hasAnyTag(entry, tags)
+		for _, alias := range aliases {
+			matches = matches || helpers.SliceContains(entryAliases, alias)
+		}
 
-			if dryRun {
-				cmd.Print(helpers.PrintFileWithSpacer(hostsFilePath, hosts))
+		if owner != "" {
+			if len(aliases) == 0 && len(tags) == 0 {
+				matches = true // all entries of owner
 			}
+			if !matches || !entry.HasOwner(owner) {
+				return false
+			}
+			if entry.RemoveOwner(owner) > 0 {
+				result.released++
+
+				return false
+			}
+
+			return true
 		}
 
-		if sshConfigFilePath == "" {
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				cmd.Printf("Error retrieving user's home directory: %v", err)
+		if matches && !force && !entry.Managed() {
+			result.skipped = append(result.skipped, fmt.Sprintf("'%s' in %s", strings.Join(entryAliases, " "), path))
+
+			return false
+		}
+
+		return matches
+	}
 
-				os.Exit(1)
+	if hosts != nil {
+		result.hosts = hosts.RemoveEntriesFunc(func(host *files.Host) bool {
+			return !host.Required() && selected(hosts.Filepath(), host.Aliases(), host)
+		})
+	}
+	result.blocks = sshConfig.RemoveBlocksFunc(func(block *files.HostBlock) bool {
+		return block.Kind != "" && selected(sshConfig.Filepath(), block.Hosts, block)
+	})
+
+	return result
+}
+
+// staleKnownHostsNames returns the known_hosts names of the aliases and addresses of removed entries which are
+// not used by any remaining entry
+func staleKnownHostsNames(hosts *files.Hosts, sshConfig *files.SSHConfig, removed *removal) []string {
+	unused := func(candidates []string) []string {
+		stale := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			if !inUse(hosts, sshConfig, candidate) {
+				stale = append(stale, candidate)
 			}
-			sshConfigFilePath = fmt.Sprintf("%s/.ssh/config", homeDir)
 		}
-		sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
-		if err != nil {
-			cmd.Printf("Error reading file: %v", err)
+		return stale
+	}
 
-			os.Exit(1)
+	names := make([]string, 0, 10)
+	for _, entry := range removed.hosts {
+		names = append(names, knownHostsNames(unused(append([]string{entry.Address()}, entry.Aliases()...)), "")...)
+	}
+	for _, block := range removed.blocks {
+		port, _ := block.GetProp("Port")
+		names = append(names, knownHostsNames(unused(blockHosts(block)), port)...)
+	}
+
+	return names
+}
+
+// taggedAliases returns the aliases of all entries with one of tags
+func taggedAliases(hosts *files.Hosts, sshConfig *files.SSHConfig, tags []string) []string {
+	aliases := make([]string, 0, 10)
+	if len(tags) == 0 {
+		return aliases
+	}
+
+	if hosts != nil {
+		for _, entry := range hosts.Entries() {
+			if hasAnyTag(entry, tags) {
+				aliases = append(aliases, entry.Aliases()...)
+			}
+		}
+	}
+	for _, block := range sshConfig.Blocks() {
+		if hasAnyTag(block, tags) {
+			aliases = append(aliases, block.Hosts...)
 		}
+	}
+
+	return helpers.UniqueStrings(aliases)
+}
 
-		sshConfig.RemoveHosts(args)
+func selectHosts(options []string, preselected []string) ([]string, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
 
-		if !dryRun {
-			sshConfig.Write()
+	defaults := make([]string, 0, len(preselected))
+	for _, host := range preselected {
+		if helpers.SliceContains(options, host) {
+			defaults = append(defaults, host)
 		}
+	}
 
-		if dryRun {
-			cmd.Print(helpers.PrintFile(sshConfigFilePath, sshConfig))
+	selected := []string{}
+	prompt := &survey.MultiSelect{
+		Message:  "Select host entries to remove (type to filter):",
+		Options:  options,
+		Default:  defaults,
+		PageSize: 15,
+	}
+	err := survey.AskOne(prompt, &selected)
+
+	return selected, err
+}
+
+func previewRemoval(removedHosts []*files.Host, removedBlocks []*files.HostBlock) string {
+	var preview strings.Builder
+	if len(removedHosts) > 0 {
+		preview.WriteString(hostsFilePath + ":\n")
+		for _, host := range removedHosts {
+			preview.WriteString("- " + host.String() + "\n")
 		}
-	},
+	}
+	if len(removedBlocks) > 0 {
+		preview.WriteString(sshConfigFilePath + ":\n")
+		for _, block := range removedBlocks {
+			for _, line := range strings.Split(strings.TrimSuffix(block.String(), "\n"), "\n") {
+				preview.WriteString("- " + line + "\n")
+			}
+		}
+	}
+	if preview.Len() == 0 {
+		preview.WriteString("No matching entries found.\n")
+	}
+
+	return preview.String()
 }
 
-func init() {
-	rootCmd.AddCommand(rmCmd)
+func exitOnPromptError(cmd *cobra.Command, err error) {
+	if errors.Is(err, terminal.InterruptErr) {
+		cmd.Println("Aborted. No files changed!")
+
+		os.Exit(1)
+	}
+
+	cmd.Printf("Error prompting for input: %v", err)
 
-	// flags := rmCmd.Flags()
-	// flags.BoolVarP(&interactiveMode, "interactive", "i", false, "Interactively select host entries to remove")
+	os.Exit(1)
 }
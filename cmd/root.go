@@ -22,19 +22,66 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	osuser "os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 
+	"github.com/martinnirtl/hosts-cli/internal/diff"
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
 	"github.com/spf13/cobra"
 )
 
 var (
-	etcHosts          bool
-	dryRun            bool
-	hostsFilePath     string
-	sshConfigFilePath string
+	etcHosts           bool
+	dryRun             bool
+	dryRunFull         bool
+	noColor            bool
+	diffContext        int
+	hostsFilePath      string
+	sshConfigFilePath  string
+	knownHostsFilePath string
+	backupSuffix       string
 )
 
+// dryRunMode sets dryRun and dryRunFull from --dry-run, --dry-run=diff or --dry-run=full
+type dryRunMode struct{}
+
+func (mode *dryRunMode) String() string {
+	switch {
+	case dryRunFull:
+		return "full"
+	case dryRun:
+		return "diff"
+	default:
+		return ""
+	}
+}
+
+func (mode *dryRunMode) Set(value string) error {
+	switch value {
+	case "diff", "true":
+		dryRun, dryRunFull = true, false
+	case "full":
+		dryRun, dryRunFull = true, true
+	case "false":
+		dryRun, dryRunFull = false, false
+	default:
+		return fmt.Errorf("must be diff or full")
+	}
+
+	return nil
+}
+
+func (mode *dryRunMode) Type() string {
+	return "mode"
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "hosts",
 	Short: "Manage address mappings of SSH config and optionally entries of hosts file",
@@ -59,9 +106,13 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Only print updated /etc/hosts and ~/.ssh/config files")
+	rootCmd.PersistentFlags().Var(&dryRunMode{}, "dry-run", "Only print changes to /etc/hosts and ~/.ssh/config files as unified diff; use --dry-run=full to print updated files")
+	rootCmd.PersistentFlags().Lookup("dry-run").NoOptDefVal = "diff"
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Print diffs without colors")
+	rootCmd.PersistentFlags().IntVar(&diffContext, "diff-context", 3, "Number of context lines of diffs printed by --dry-run")
 	rootCmd.PersistentFlags().StringVar(&sshConfigFilePath, "ssh-config", "", "Set SSH Config file (e.g. /etc/ssh/config); default: ~/.ssh/config")
 	rootCmd.PersistentFlags().StringVar(&hostsFilePath, "hosts-file", "", "Set host file (e.g. ~/hosts); default: /etc/hosts")
+	rootCmd.PersistentFlags().StringVar(&knownHostsFilePath, "known-hosts-file", "", "Set known_hosts file; default: ~/.ssh/known_hosts")
 	rootCmd.PersistentFlags().BoolVar(&etcHosts, "etc-hosts", false, "Additionally add entry to /etc/hosts file (requires sudo)")
 }
 
@@ -69,13 +120,385 @@ func getFilePaths() error {
 	if hostsFilePath == "" {
 		hostsFilePath = "/etc/hosts"
 	}
-	if sshConfigFilePath == "" {
+	if sshConfigFilePath == "" || knownHostsFilePath == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			return err
 		}
-		sshConfigFilePath = fmt.Sprintf("%s/.ssh/config", homeDir)
+		if sshConfigFilePath == "" {
+			sshConfigFilePath = fmt.Sprintf("%s/.ssh/config", homeDir)
+		}
+		if knownHostsFilePath == "" {
+			knownHostsFilePath = fmt.Sprintf("%s/.ssh/known_hosts", homeDir)
+		}
+	}
+
+	return nil
+}
+
+// tagged is implemented by hosts file entries and ssh config blocks
+type tagged interface {
+	HasTag(tag string) bool
+}
+
+// managedEntry is implemented by hosts file entries and ssh config blocks
+type managedEntry interface {
+	tagged
+	Managed() bool
+}
+
+// removableEntry is implemented by hosts file entries and ssh config blocks
+type removableEntry interface {
+	managedEntry
+	HasOwner(owner string) bool
+	RemoveOwner(owner string) int
+}
+
+// hasAnyTag reports whether entry carries at least one of tags
+func hasAnyTag(entry tagged, tags []string) bool {
+	for _, tag := range tags {
+		if entry.HasTag(tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateTags(cmd *cobra.Command, tags []string) {
+	for _, tag := range tags {
+		if !helpers.IsValidTag(tag) {
+			cmd.Printf("'%s' is not a valid tag. Use letters, digits, '.', '_' and '-'!\n", tag)
+
+			os.Exit(1)
+		}
+	}
+}
+
+func validateOwner(cmd *cobra.Command, owner string) {
+	if owner != "" && !helpers.IsValidOwner(owner) {
+		cmd.Printf("'%s' is not a valid owner. Use letters, digits, '.', '_' and '-'!\n", owner)
+
+		os.Exit(1)
+	}
+}
+
+// hostsOrTags requires host names as args or at least one --tag
+func hostsOrTags(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && len(tags) == 0 {
+		return fmt.Errorf("requires at least 1 host name or --tag")
+	}
+
+	return nil
+}
+
+// readFiles reads ssh-config and, with --etc-hosts, the hosts file; hosts is nil otherwise
+func readFiles(cmd *cobra.Command) (*files.Hosts, *files.SSHConfig) {
+	err := getFilePaths()
+	if err != nil {
+		cmd.Printf("Error retrieving file paths: %v", err)
+
+		os.Exit(1)
+	}
+
+	var hosts *files.Hosts
+	if etcHosts {
+		hosts, err = files.GetHosts(hostsFilePath)
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+	}
+
+	sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+	if err != nil {
+		cmd.Printf("Error reading file: %v", err)
+
+		os.Exit(1)
+	}
+
+	return hosts, sshConfig
+}
+
+// targetFiles returns hosts (if loaded) and sshConfig for replaceFiles
+func targetFiles(hosts *files.Hosts, sshConfig *files.SSHConfig) []file {
+	targets := make([]file, 0, 2)
+	if hosts != nil {
+		targets = append(targets, hosts)
+	}
+
+	return append(targets, sshConfig)
+}
+
+// forgetKnownHosts removes the keys of names from known_hosts and returns it as target if it changed
+func forgetKnownHosts(cmd *cobra.Command, names []string) []file {
+	knownHosts, err := files.GetKnownHosts(knownHostsFilePath)
+	if err != nil {
+		cmd.Printf("Error reading file: %v", err)
+
+		os.Exit(1)
+	}
+
+	changed := 0
+	for _, name := range helpers.UniqueStrings(names) {
+		changed += knownHosts.RemoveHost(name)
+	}
+	if changed == 0 {
+		return nil
+	}
+
+	cmd.Printf("Removing stale keys from %s\n", knownHostsFilePath)
+
+	return []file{knownHosts}
+}
+
+// knownHostsNames returns the names ssh records keys of hosts under, with and without a non-default port
+func knownHostsNames(hosts []string, port string) []string {
+	names := make([]string, 0, 2*len(hosts))
+	for _, host := range hosts {
+		if host == "" || strings.ContainsAny(host, "*?!") {
+			continue // patterns
+		}
+		names = append(names, host)
+		if name := files.KnownHostsName(host, port); name != host {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// blockHosts returns the hosts and HostName of block
+func blockHosts(block *files.HostBlock) []string {
+	hostname, _ := block.GetProp("HostName")
+
+	return append(append([]string{}, block.Hosts...), hostname)
+}
+
+// inUse reports whether host is still an alias or address of one of the entries; hosts may be nil
+func inUse(hosts *files.Hosts, sshConfig *files.SSHConfig, host string) bool {
+	if hosts != nil {
+		for _, entry := range hosts.Entries() {
+			if entry.Address() == host || helpers.SliceContains(entry.Aliases(), host) {
+				return true
+			}
+		}
+	}
+	for _, block := range sshConfig.Blocks() {
+		if block.Kind != "" && helpers.SliceContains(blockHosts(block), host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sudoUser returns the user who ran the CLI through sudo or nil, e.g. to keep state in their config directory
+func sudoUser() *osuser.User {
+	name := os.Getenv("SUDO_USER")
+	if name == "" || os.Geteuid() != 0 {
+		return nil
+	}
+
+	sudoer, err := osuser.Lookup(name)
+	if err != nil {
+		return nil
+	}
+
+	return sudoer
+}
+
+// configDir returns the config directory of the user running the CLI, which is the invoking user under sudo
+func configDir() (string, error) {
+	sudoer := sudoUser()
+	if sudoer == nil {
+		return os.UserConfigDir()
+	}
+
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(sudoer.HomeDir, "Library", "Application Support"), nil
+	}
+
+	return filepath.Join(sudoer.HomeDir, ".config"), nil
+}
+
+// stateDir returns the directory holding the files as last written by the CLI for 'hosts check'
+func stateDir() (string, error) {
+	configDir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "hosts-cli", "state"), nil
+}
+
+// saveState records the written content of path; failures only print a warning as the files were written
+func saveState(cmd *cobra.Command, path string, content []byte) {
+	dir, err := stateDir()
+	if err == nil {
+		err = files.SaveState(dir, path, content)
+	}
+	if err == nil {
+		err = chownState(dir, path)
+	}
+	if err != nil {
+		cmd.Printf("Warning: failed saving state of %s: %v\n", path, err)
+	}
+}
+
+// chownState hands the state of path to the invoking user under sudo, who could not update it otherwise
+func chownState(dir string, path string) error {
+	return chownSudoUser(filepath.Dir(filepath.Dir(dir)), filepath.Dir(dir), dir, files.StatePath(dir, path))
+}
+
+// chownSudoUser hands the files or directories names to the invoking user under sudo
+func chownSudoUser(names ...string) error {
+	sudoer := sudoUser()
+	if sudoer == nil {
+		return nil
+	}
+
+	uid, err := strconv.Atoi(sudoer.Uid)
+	if err != nil {
+		return nil
+	}
+	gid, err := strconv.Atoi(sudoer.Gid)
+	if err != nil {
+		return nil
+	}
+
+	for _, name := range names {
+		if err := os.Lchown(name, uid, gid); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// file is implemented by all files written by the CLI
+type file interface {
+	Filepath() string
+	Bytes() []byte
+}
+
+// writeFiles writes hosts (if loaded) and sshConfig or prints their changes in dry-run mode
+func writeFiles(cmd *cobra.Command, hosts *files.Hosts, sshConfig *files.SSHConfig) {
+	replaceFiles(cmd, targetFiles(hosts, sshConfig)...)
+}
+
+// replaceFiles writes all targets in a single transaction or prints their changes in dry-run mode
+func replaceFiles(cmd *cobra.Command, targets ...file) {
+	if dryRun {
+		printDryRun(cmd, targets...)
+
+		return
+	}
+
+	tx := files.NewTransaction()
+	tx.Backup(backupSuffix)
+	for _, target := range targets {
+		if created, ok := target.(*newFile); ok {
+			tx.Create(created.path, created.content, created.perm)
+			continue
+		}
+		tx.Stage(target.Filepath(), target.Bytes())
+	}
+
+	if err := tx.Commit(); err != nil {
+		var rollbackErr *files.RollbackError
+		if errors.As(err, &rollbackErr) {
+			cmd.Printf("Error writing files: %v\n", err)
+			cmd.Printf("Could not restore %s, which may hold partial changes!\n", strings.Join(rollbackErr.Paths, ", "))
+			for _, backup := range tx.Backups() {
+				cmd.Printf("Saved backup to %s\n", backup)
+			}
+		} else {
+			cmd.Printf("Error writing files, no changes made: %v\n", err)
+		}
+
+		os.Exit(1)
+	}
+
+	for _, target := range targets {
+		if created, ok := target.(*newFile); ok {
+			cmd.Printf("Created %s\n", created.path)
+			continue
+		}
+		saveState(cmd, target.Filepath(), target.Bytes())
+	}
+	for _, backup := range tx.Backups() {
+		cmd.Printf("Saved backup to %s\n", backup)
+	}
+}
+
+// fileContent is a file given by its path and content, e.g. edited in an editor
+type fileContent struct {
+	path    string
+	content []byte
+}
+
+func (file *fileContent) Filepath() string {
+	return file.path
+}
+
+func (file *fileContent) Bytes() []byte {
+	return file.content
+}
+
+// newFile is a file which must not exist yet, e.g. a key in ~/.ssh, written with perm
+type newFile struct {
+	path    string
+	content []byte
+	perm    os.FileMode
+}
+
+func (file *newFile) Filepath() string {
+	return file.path
+}
+
+func (file *newFile) Bytes() []byte {
+	return file.content
+}
+
+// printDryRun prints the changes to all targets as unified diffs or, with --dry-run=full, the updated files
+func printDryRun(cmd *cobra.Command, targets ...file) {
+	if dryRunFull {
+		for i, target := range targets {
+			if i < len(targets)-1 {
+				cmd.Print(helpers.PrintFileWithSpacer(target.Filepath(), string(target.Bytes())))
+			} else {
+				cmd.Print(helpers.PrintFile(target.Filepath(), string(target.Bytes())))
+			}
+		}
+
+		return
+	}
+
+	if diffContext < 0 {
+		cmd.Println("Diff context must not be negative!")
+
+		os.Exit(1)
+	}
+
+	for _, target := range targets {
+		current, err := os.ReadFile(target.Filepath())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		unified := diff.Unified(target.Filepath(), target.Filepath()+" (dry-run)", string(current), string(target.Bytes()), diffContext)
+		if unified == "" {
+			cmd.Printf("No changes to %s\n", target.Filepath())
+			continue
+		}
+
+		if !noColor && os.Getenv("NO_COLOR") == "" && helpers.IsTerminal() {
+			unified = diff.Colorize(unified)
+		}
+		cmd.Print(unified)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+// setCmd represents the set command
+var setCmd = &cobra.Command{
+	Use:   "set ALIAS KEYWORD VALUE...",
+	Short: "Set a property of an existing Host block in ssh-config",
+	Long: `Set a property of an existing Host block in ssh-config, e.g. 'hosts set web01 Port 2222'.
+  Keywords are validated and written in their canonical casing. All other properties are kept! A Host block
+  shared with other aliases is split, so that the property is only set for the given alias.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting host name")
+		} else if len(args) == 1 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting ssh_config keyword; e.g. User or Port")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Expecting value or enter key")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.MinimumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		keyword := canonicalPropKeyword(cmd, args[1])
+		value := strings.Join(args[2:], " ")
+		if err := validatePropValue(keyword, value); err != nil {
+			cmd.Printf("%v\n", err)
+
+			os.Exit(1)
+		}
+
+		err := getFilePaths()
+		if err != nil {
+			cmd.Printf("Error retrieving file paths: %v", err)
+
+			os.Exit(1)
+		}
+
+		sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		block := findHostBlock(cmd, sshConfig, args[0])
+		sshConfig.SplitHost(block, args[0]).SetProp(keyword, value)
+
+		writeFiles(cmd, nil, sshConfig)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setCmd)
+}
+
+// canonicalPropKeyword validates keyword as Host block property and returns its canonical casing
+func canonicalPropKeyword(cmd *cobra.Command, keyword string) string {
+	canonical, err := propKeyword(keyword)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+
+		os.Exit(1)
+	}
+
+	return canonical
+}
+
+func validatePropValue(keyword string, value string) error {
+	if err := files.ValidateSSHConfig(keyword + " " + value); err != nil {
+		return fmt.Errorf("Invalid value for %s: %v", keyword, strings.TrimPrefix(err.Error(), "line 1: "))
+	}
+
+	return nil
+}
+
+func propKeyword(keyword string) (string, error) {
+	canonical, ok := files.CanonicalKeyword(keyword)
+	if !ok {
+		return "", fmt.Errorf("Unknown ssh_config keyword '%s'", keyword)
+	}
+
+	switch canonical {
+	case "Host", "Match", "Include":
+		return "", fmt.Errorf("Keyword '%s' can not be used as property", canonical)
+	}
+
+	return canonical, nil
+}
+
+// findHostBlock returns the first Host block of alias, which is the one ssh takes values from
+func findHostBlock(cmd *cobra.Command, sshConfig *files.SSHConfig, alias string) *files.HostBlock {
+	block, err := hostBlock(sshConfig, alias)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+
+		os.Exit(1)
+	}
+
+	return block
+}
+
+func hostBlock(sshConfig *files.SSHConfig, alias string) (*files.HostBlock, error) {
+	blocks := sshConfig.FindBlocks(alias)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("No Host block found for '%s' in %s", alias, sshConfig.Filepath())
+	}
+
+	return blocks[0], nil
+}
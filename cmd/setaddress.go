@@ -0,0 +1,193 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"net"
+	"os"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+// setAddressCmd represents the set-address command
+var setAddressCmd = &cobra.Command{
+	Use:   "set-address ALIAS ADDRESS",
+	Short: "Point an alias to a new address in ssh-config and hosts file",
+	Long: `Point an alias to a new address by updating the HostName of its Host blocks and its hosts file entry.
+  Other aliases, comments and properties are kept! Aliases sharing a Host block or hosts line with the alias
+  keep their address; the alias is moved to a copy of the block or to a hosts line of the new address.
+  Use --known-hosts to also remove the keys recorded for the alias and its previous address from known_hosts.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting host name")
+		} else if len(args) == 1 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting new address/IP")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Too many arguments specified!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		alias, address := args[0], args[1]
+		if !helpers.IsValidAddress(address) {
+			cmd.Printf("'%s' is not a valid IP address or domain\n", address)
+
+			os.Exit(1)
+		}
+		if etcHosts && net.ParseIP(address) == nil {
+			cmd.Printf("The hosts file requires an IP address, got '%s'\n", address)
+
+			os.Exit(1)
+		}
+
+		err := getFilePaths()
+		if err != nil {
+			cmd.Printf("Error retrieving file paths: %v", err)
+
+			os.Exit(1)
+		}
+
+		var hosts *files.Hosts
+		if etcHosts {
+			hosts, err = files.GetHosts(hostsFilePath)
+			if err != nil {
+				cmd.Printf("Error reading file: %v", err)
+
+				os.Exit(1)
+			}
+		}
+
+		sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		if !helpers.SliceContains(listAliases(hosts, sshConfig), alias) {
+			cmd.Printf("No entries found for '%s'\n", alias)
+
+			os.Exit(1)
+		}
+
+		var stale []string
+		if cleanKnownHosts {
+			stale = previousKnownHostsNames(hosts, sshConfig, alias, address)
+		}
+
+		targets := make([]file, 0, 3)
+		if hosts != nil {
+			hosts.SetAddress(alias, address)
+			targets = append(targets, hosts)
+		}
+
+		setHostName(sshConfig, alias, address)
+		targets = append(targets, sshConfig)
+
+		if cleanKnownHosts {
+			targets = append(targets, forgetKnownHosts(cmd, stale)...)
+		}
+
+		replaceFiles(cmd, targets...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setAddressCmd)
+
+	flags := setAddressCmd.Flags()
+	flags.BoolVar(&cleanKnownHosts, "known-hosts", false, "Also remove the keys of the alias and its previous address from known_hosts, including hashed entries")
+}
+
+// previousKnownHostsNames returns the known_hosts names of alias and its current addresses, which are stale
+// once it points to newAddress. Addresses still used by other entries are kept.
+func previousKnownHostsNames(hosts *files.Hosts, sshConfig *files.SSHConfig, alias string, newAddress string) []string {
+	port := ""
+	addresses := make([]string, 0, 2)
+	if hosts != nil {
+		for _, entry := range hosts.FindEntries(alias) {
+			addresses = append(addresses, entry.Address())
+		}
+	}
+	for _, block := range sshConfig.FindBlocks(alias) {
+		if hostname, ok := block.GetProp("HostName"); ok {
+			addresses = append(addresses, hostname)
+		}
+		if value, ok := block.GetProp("Port"); ok && port == "" {
+			port = value
+		}
+	}
+
+	names := knownHostsNames([]string{alias}, port)
+	for _, address := range addresses {
+		if address != newAddress && !usedByOthers(hosts, sshConfig, alias, address) {
+			names = append(names, knownHostsNames([]string{address}, port)...)
+		}
+	}
+
+	return names
+}
+
+// usedByOthers reports whether address is used by an entry not mapping alias
+func usedByOthers(hosts *files.Hosts, sshConfig *files.SSHConfig, alias string, address string) bool {
+	if hosts != nil {
+		for _, entry := range hosts.Entries() {
+			if entry.Address() == address && !helpers.SliceContains(entry.Aliases(), alias) {
+				return true
+			}
+		}
+	}
+	for _, block := range sshConfig.Blocks() {
+		hostname, _ := block.GetProp("HostName")
+		if block.Kind != "" && hostname == address && !helpers.SliceContains(block.Hosts, alias) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setHostName updates HostName of all blocks of alias defining one or sets it on the first block otherwise.
+// Blocks shared with other aliases are split first, so that those keep their HostName.
+func setHostName(sshConfig *files.SSHConfig, alias string, address string) {
+	blocks := make([]*files.HostBlock, 0, 1)
+	for _, block := range sshConfig.FindBlocks(alias) {
+		if _, ok := block.GetProp("HostName"); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		blocks = sshConfig.FindBlocks(alias)
+		if len(blocks) == 0 {
+			return
+		}
+		blocks = blocks[:1]
+	}
+
+	for _, block := range blocks {
+		sshConfig.SplitHost(block, alias).SetProp("HostName", address)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2023 Martin Nirtl <martin.nirtl@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+	"github.com/spf13/cobra"
+)
+
+// unsetCmd represents the unset command
+var unsetCmd = &cobra.Command{
+	Use:   "unset ALIAS KEYWORD",
+	Short: "Remove a property from an existing Host block in ssh-config",
+	Long: `Remove a property from an existing Host block in ssh-config, e.g. 'hosts unset web01 IdentityFile'.
+  All other properties are kept! A Host block shared with other aliases is split, so that the property is
+  only removed for the given alias.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		if len(args) == 0 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting host name")
+		} else if len(args) == 1 {
+			comps = cobra.AppendActiveHelp(comps, "Expecting ssh_config keyword; e.g. User or Port")
+		} else {
+			comps = cobra.AppendActiveHelp(comps, "Too many arguments specified!")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		keyword := canonicalPropKeyword(cmd, args[1])
+		if keyword == "HostName" {
+			cmd.Println("Keyword 'HostName' can not be removed. Use 'hosts rm' to remove the host!")
+
+			os.Exit(1)
+		}
+
+		err := getFilePaths()
+		if err != nil {
+			cmd.Printf("Error retrieving file paths: %v", err)
+
+			os.Exit(1)
+		}
+
+		sshConfig, err := files.GetSSHConfig(sshConfigFilePath)
+		if err != nil {
+			cmd.Printf("Error reading file: %v", err)
+
+			os.Exit(1)
+		}
+
+		block := findHostBlock(cmd, sshConfig, args[0])
+		if _, ok := block.GetProp(keyword); !ok {
+			cmd.Printf("Property '%s' not set for '%s'. Nothing to do!\n", keyword, args[0])
+
+			return
+		}
+		sshConfig.SplitHost(block, args[0]).RemoveProp(keyword)
+
+		writeFiles(cmd, nil, sshConfig)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unsetCmd)
+}
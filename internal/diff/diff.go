@@ -0,0 +1,266 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+func (line Line) String() string {
+	switch line.Op {
+	case Insert:
+		return "+" + line.Text
+	case Delete:
+		return "-" + line.Text
+	default:
+		return " " + line.Text
+	}
+}
+
+// Lines computes the shortest edit script turning a into b. It uses the linear space variant of Myers'
+// algorithm, so memory stays O(len(a)+len(b)) even for large files with many changes.
+func Lines(a, b []string) []Line {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+
+	d := &differ{a: a, b: b, lines: make([]Line, 0, len(a)+len(b))}
+	d.compare(0, len(a), 0, len(b))
+
+	return d.lines
+}
+
+type differ struct {
+	a, b  []string
+	lines []Line
+}
+
+// compare appends the edit script of a[aLo:aHi] to b[bLo:bHi]
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.lines = append(d.lines, Line{Op: Equal, Text: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+
+	switch {
+	case aLo == aHi:
+		for _, text := range d.b[bLo:bHi] {
+			d.lines = append(d.lines, Line{Op: Insert, Text: text})
+		}
+	case bLo == bHi:
+		for _, text := range d.a[aLo:aHi] {
+			d.lines = append(d.lines, Line{Op: Delete, Text: text})
+		}
+	default:
+		// without common prefix and suffix the distance is at least 2, so both halves are smaller
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, aLo+x, bLo, bLo+y)
+		for _, text := range d.a[aLo+x : aLo+u] {
+			d.lines = append(d.lines, Line{Op: Equal, Text: text})
+		}
+		d.compare(aLo+u, aHi, bLo+v, bHi)
+	}
+
+	for _, text := range d.a[aHi : aHi+suffix] {
+		d.lines = append(d.lines, Line{Op: Equal, Text: text})
+	}
+}
+
+// middleSnake searches shortest paths from the start and the end of both ranges at once until they overlap and
+// returns the snake (x,y)-(u,v) where they meet, relative to aLo and bLo
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+	offset := max + 1
+
+	// furthest x on diagonal k, forward from (0,0) and backward from (n,m) in reversed coordinates
+	forward := make([]int, 2*max+3)
+	backward := make([]int, 2*max+3)
+
+	for step := 0; step <= max; step++ {
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			if reverseK := delta - k; odd && reverseK >= -(step-1) && reverseK <= step-1 {
+				if x+backward[offset+reverseK] >= n {
+					return startX, startY, x, y
+				}
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			if forwardK := delta - k; !odd && forwardK >= -step && forwardK <= step {
+				if x+forward[offset+forwardK] >= n {
+					return n - x, m - y, n - startX, m - startY
+				}
+			}
+		}
+	}
+
+	return 0, 0, 0, 0 // unreachable
+}
+
+// Unified returns a unified diff of both texts or an empty string if they are equal
+func Unified(fromName, toName string, a, b string, context int) string {
+	lines := Lines(splitLines(a), splitLines(b))
+
+	// positions of each line within a and b
+	aPos := make([]int, len(lines)+1)
+	bPos := make([]int, len(lines)+1)
+	for i, line := range lines {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if line.Op != Insert {
+			aPos[i+1]++
+		}
+		if line.Op != Delete {
+			bPos[i+1]++
+		}
+	}
+
+	var output strings.Builder
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].Op == Equal {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		end := i
+		for {
+			for end < len(lines) && lines[end].Op != Equal {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].Op == Equal {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*context {
+				end = next // merge with following change
+
+				continue
+			}
+
+			end += context
+			if end > len(lines) {
+				end = len(lines)
+			}
+
+			break
+		}
+
+		if output.Len() == 0 {
+			fmt.Fprintf(&output, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		fmt.Fprintf(&output, "@@ -%s +%s @@\n", hunkRange(aPos[start], aPos[end]), hunkRange(bPos[start], bPos[end]))
+		for _, line := range lines[start:end] {
+			output.WriteString(line.String() + "\n")
+		}
+
+		i = end
+	}
+
+	return output.String()
+}
+
+func hunkRange(start, end int) string {
+	count := end - start
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// Colorize highlights a unified diff with ANSI colors like git diff does
+func Colorize(unified string) string {
+	lines := splitLines(unified)
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ "):
+			lines[i] = colorBold + line + colorReset
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = colorCyan + line + colorReset
+		case strings.HasPrefix(line, "-"):
+			lines[i] = colorRed + line + colorReset
+		case strings.HasPrefix(line, "+"):
+			lines[i] = colorGreen + line + colorReset
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,200 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// apply rebuilds both sides of an edit script
+func apply(lines []Line) ([]string, []string) {
+	var a, b []string
+	for _, line := range lines {
+		if line.Op != Insert {
+			a = append(a, line.Text)
+		}
+		if line.Op != Delete {
+			b = append(b, line.Text)
+		}
+	}
+
+	return a, b
+}
+
+func edits(lines []Line) int {
+	count := 0
+	for _, line := range lines {
+		if line.Op != Equal {
+			count++
+		}
+	}
+
+	return count
+}
+
+// lcsDistance returns the minimal number of inserts and deletes turning a into b
+func lcsDistance(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] > lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		edits int
+	}{
+		{"empty", "", "", 0},
+		{"equal", "a b c", "a b c", 0},
+		{"insert into empty", "", "a b", 2},
+		{"delete all", "a b", "", 2},
+		{"append", "a b", "a b c", 1},
+		{"prepend", "b c", "a b c", 1},
+		{"replace middle", "a b c", "a x c", 2},
+		{"replace all", "a b c", "x y z", 6},
+		{"swap", "a b", "b a", 2},
+		{"myers paper", "a b c a b b a", "c b a b a c", 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := strings.Fields(test.a), strings.Fields(test.b)
+			lines := Lines(a, b)
+
+			gotA, gotB := apply(lines)
+			if strings.Join(gotA, " ") != test.a || strings.Join(gotB, " ") != test.b {
+				t.Fatalf("script rebuilds %q and %q", gotA, gotB)
+			}
+			if got := edits(lines); got != test.edits {
+				t.Errorf("got %d edits, want %d", got, test.edits)
+			}
+		})
+	}
+}
+
+func TestLinesMinimal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	words := func() []string {
+		out := make([]string, random.Intn(30))
+		for i := range out {
+			out[i] = string(rune('a' + random.Intn(4)))
+		}
+		return out
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := words(), words()
+		lines := Lines(a, b)
+
+		gotA, gotB := apply(lines)
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("script of %q -> %q rebuilds %q and %q", a, b, gotA, gotB)
+		}
+		if got, want := edits(lines), lcsDistance(a, b); got != want {
+			t.Fatalf("%q -> %q: got %d edits, want %d", a, b, got, want)
+		}
+	}
+}
+
+// TestLinesLarge covers hosts files like ad-block lists, which exhausted memory with a trace of all steps
+func TestLinesLarge(t *testing.T) {
+	a := make([]string, 50000)
+	for i := range a {
+		a[i] = fmt.Sprintf("0.0.0.0 ads-%d.example.com", i)
+	}
+
+	b := make([]string, 0, len(a))
+	for i, line := range a {
+		if i%7 == 0 {
+			continue
+		}
+		b = append(b, line)
+		if i%11 == 0 {
+			b = append(b, fmt.Sprintf("0.0.0.0 new-%d.example.com", i))
+		}
+	}
+
+	lines := Lines(a, b)
+	gotA, gotB := apply(lines)
+	if len(gotA) != len(a) || len(gotB) != len(b) {
+		t.Fatalf("script rebuilds %d and %d lines, want %d and %d", len(gotA), len(gotB), len(a), len(b))
+	}
+	if want := len(a) - len(b) + 2*(len(a)/11+1) - 2*(len(a)/77+1); edits(lines) != want {
+		t.Errorf("got %d edits, want %d", edits(lines), want)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name:    "append to empty",
+			a:       "",
+			b:       "a\n",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:    "change with context",
+			a:       "1\n2\n3\n4\n5\n",
+			b:       "1\n2\nx\n4\n5\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2,3 +2,3 @@\n 2\n-3\n+x\n 4\n",
+		},
+		{
+			name:    "separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n",
+			b:       "x\n2\n3\n4\n5\n6\ny\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -6,2 +6,2 @@\n 6\n-7\n+y\n",
+		},
+		{
+			name:    "merged hunks",
+			a:       "1\n2\n3\n4\n",
+			b:       "x\n2\n3\ny\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y\n",
+		},
+		{
+			name:    "without context",
+			a:       "1\n2\n3\n",
+			b:       "1\n3\n",
+			context: 0,
+			want:    "--- old\n+++ new\n@@ -2 +1,0 @@\n-2\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Unified("old", "new", test.a, test.b, test.context); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxExpansion limits the number of values a range or CIDR expands to, e.g. to catch a mistyped /8
+const MaxExpansion = 1024
+
+var rangeRegexp = regexp.MustCompile(`\[(\d+)-(\d+)\]`)
+
+// ExpandRange expands numeric ranges in pattern, e.g. node-[0-2] to node-0, node-1 and node-2. A leading
+// zero pads all numbers to the width of the start, e.g. [08-10] to 08, 09 and 10. Several ranges expand to
+// all combinations.
+func ExpandRange(pattern string) ([]string, error) {
+	loc := rangeRegexp.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		if strings.ContainsAny(pattern, "[]") {
+			return nil, fmt.Errorf("invalid range in '%s'; expecting e.g. [0-9]", pattern)
+		}
+
+		return []string{pattern}, nil
+	}
+
+	prefix := pattern[:loc[0]]
+	if strings.ContainsAny(prefix, "[]") {
+		return nil, fmt.Errorf("invalid range in '%s'; expecting e.g. [0-9]", pattern)
+	}
+
+	startText := pattern[loc[2]:loc[3]]
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range in '%s': %v", pattern, err)
+	}
+	end, err := strconv.Atoi(pattern[loc[4]:loc[5]])
+	if err != nil {
+		return nil, fmt.Errorf("invalid range in '%s': %v", pattern, err)
+	}
+	if start > end {
+		return nil, fmt.Errorf("invalid range in '%s': %d is greater than %d", pattern, start, end)
+	}
+
+	width := 0
+	if len(startText) > 1 && startText[0] == '0' {
+		width = len(startText)
+	}
+
+	suffixes, err := ExpandRange(pattern[loc[1]:])
+	if err != nil {
+		return nil, err
+	}
+	// checked before multiplying, which overflows for ranges like [0-9223372036854775807]
+	if end-start >= MaxExpansion || len(suffixes) > MaxExpansion/(end-start+1) {
+		return nil, fmt.Errorf("'%s' expands to more than %d values", pattern, MaxExpansion)
+	}
+
+	expanded := make([]string, 0, (end-start+1)*len(suffixes))
+	for i := 0; i <= end-start; i++ { // i <= end would overflow for end = MaxInt
+		for _, suffix := range suffixes {
+			expanded = append(expanded, fmt.Sprintf("%s%0*d%s", prefix, width, start+i, suffix))
+		}
+	}
+
+	return expanded, nil
+}
+
+// ExpandCIDR returns all host addresses of cidr, e.g. 10.0.1.1 to 10.0.1.14 for 10.0.1.0/28. The network
+// address and, for IPv4, the broadcast address are skipped unless the network is a /31 or /32 (/127 or /128).
+func ExpandCIDR(cidr string) ([]string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid CIDR; expecting e.g. 10.0.1.0/28", cidr)
+	}
+	prefix = prefix.Masked()
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 10 {
+		return nil, fmt.Errorf("'%s' expands to more than %d addresses", cidr, MaxExpansion)
+	}
+
+	addresses := make([]string, 0, 1<<hostBits)
+	for addr := prefix.Addr(); addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+		addresses = append(addresses, addr.String())
+	}
+
+	if hostBits > 1 {
+		addresses = addresses[1:] // network address
+		if prefix.Addr().Is4() {
+			addresses = addresses[:len(addresses)-1] // broadcast address
+		}
+	}
+
+	return addresses, nil
+}
@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandRange(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		count   int
+		err     bool
+	}{
+		{pattern: "node", want: "node"},
+		{pattern: "node-[0-2]", want: "node-0 node-1 node-2"},
+		{pattern: "node-[2-2]", want: "node-2"},
+		{pattern: "[1-2].lab", want: "1.lab 2.lab"},
+		{pattern: "node-[08-10]", want: "node-08 node-09 node-10"},
+		{pattern: "node-[0-1]-[a-b]", err: true},
+		{pattern: "r[1-2]n[0-1]", want: "r1n0 r1n1 r2n0 r2n1"},
+		{pattern: "10.0.1.[254-255]", want: "10.0.1.254 10.0.1.255"},
+		{pattern: "node-[2-0]", err: true},
+		{pattern: "node-[0-]", err: true},
+		{pattern: "node-[a-c]", err: true},
+		{pattern: "node-[0-2", err: true},
+		{pattern: "node-0-2]", err: true},
+		{pattern: "node-[0-1024]", err: true},
+		{pattern: "r[0-31]n[0-31]", count: MaxExpansion},
+		{pattern: "r[0-32]n[0-31]", err: true},
+		{pattern: "node-[99999999999999999999-99999999999999999999]", err: true},
+		{pattern: "node-[0-9223372036854775807]", err: true},
+		{pattern: "r[0-4611686018427387904]n[0-1]", err: true},
+		{pattern: "node-[9223372036854775806-9223372036854775807]", want: "node-9223372036854775806 node-9223372036854775807"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			got, err := ExpandRange(test.pattern)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.count > 0 {
+				if len(got) != test.count {
+					t.Errorf("got %d values, want %d", len(got), test.count)
+				}
+			} else if strings.Join(got, " ") != test.want {
+				t.Errorf("got %v, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestExpandRangeOctets covers ranges exceeding the octets of an IP address, which must not pass validation
+func TestExpandRangeOctets(t *testing.T) {
+	addresses, err := ExpandRange("10.0.1.[254-256]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := make([]string, 0)
+	for _, address := range addresses {
+		if !IsValidAddress(address) {
+			invalid = append(invalid, address)
+		}
+	}
+	if strings.Join(invalid, " ") != "10.0.1.256" {
+		t.Errorf("got invalid addresses %v, want [10.0.1.256]", invalid)
+	}
+}
+
+func TestExpandCIDR(t *testing.T) {
+	tests := []struct {
+		cidr  string
+		count int
+		first string
+		last  string
+		err   bool
+	}{
+		{cidr: "10.0.1.0/28", count: 14, first: "10.0.1.1", last: "10.0.1.14"},
+		{cidr: "10.0.1.5/28", count: 14, first: "10.0.1.1", last: "10.0.1.14"},
+		{cidr: "10.0.1.0/30", count: 2, first: "10.0.1.1", last: "10.0.1.2"},
+		{cidr: "10.0.1.0/31", count: 2, first: "10.0.1.0", last: "10.0.1.1"},
+		{cidr: "10.0.1.7/32", count: 1, first: "10.0.1.7", last: "10.0.1.7"},
+		{cidr: "10.0.0.0/22", count: 1022, first: "10.0.0.1", last: "10.0.3.254"},
+		{cidr: "10.0.0.0/21", err: true},
+		{cidr: "255.255.255.252/30", count: 2, first: "255.255.255.253", last: "255.255.255.254"},
+		{cidr: "2001:db8::/126", count: 3, first: "2001:db8::1", last: "2001:db8::3"},
+		{cidr: "2001:db8::/127", count: 2, first: "2001:db8::", last: "2001:db8::1"},
+		{cidr: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffc/126", count: 3, first: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffd", last: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{cidr: "10.0.1.0", err: true},
+		{cidr: "10.0.1.0/33", err: true},
+		{cidr: "10.0.1.256/28", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cidr, func(t *testing.T) {
+			got, err := ExpandCIDR(test.cidr)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %d addresses, want error", len(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != test.count {
+				t.Fatalf("got %d addresses, want %d", len(got), test.count)
+			}
+			if got[0] != test.first || got[len(got)-1] != test.last {
+				t.Errorf("got %s to %s, want %s to %s", got[0], got[len(got)-1], test.first, test.last)
+			}
+			if len(UniqueStrings(got)) != len(got) {
+				t.Errorf("got duplicate addresses")
+			}
+		})
+	}
+}
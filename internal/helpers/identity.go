@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ListIdentityFiles returns all private keys found in dir, e.g. ~/.ssh
+func ListIdentityFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	identityFiles := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) == ".pub" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if isPrivateKey(path) {
+			identityFiles = append(identityFiles, path)
+		}
+	}
+	sort.Strings(identityFiles)
+
+	return identityFiles, nil
+}
+
+func isPrivateKey(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 64)
+	n, _ := file.Read(header)
+
+	return bytes.HasPrefix(header[:n], []byte("-----BEGIN")) && bytes.Contains(header[:n], []byte("PRIVATE KEY"))
+}
+
+// IdentityImportPath returns the path the private key src is imported to in dir and whether it is there already.
+// Names taken by other files get a number appended, e.g. id_ed25519-1.
+func IdentityImportPath(src string, dir string) (string, bool, error) {
+	if !isPrivateKey(src) {
+		return "", false, fmt.Errorf("'%s' is not a private key", src)
+	}
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return "", false, err
+	}
+
+	name := filepath.Base(src)
+	for i := 1; ; i++ {
+		dst := filepath.Join(dir, name)
+		existing, err := os.ReadFile(dst)
+		if errors.Is(err, os.ErrNotExist) {
+			if _, err := os.Stat(dst + ".pub"); errors.Is(err, os.ErrNotExist) {
+				return dst, false, nil
+			}
+		} else if err != nil {
+			return "", false, err
+		} else if bytes.Equal(existing, content) {
+			return dst, true, nil
+		}
+
+		name = fmt.Sprintf("%s-%d", filepath.Base(src), i)
+	}
+}
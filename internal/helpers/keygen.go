@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyTypes are the types of keys GenerateKeyPair creates
+var KeyTypes = []string{"ed25519", "rsa", "ecdsa"}
+
+// GenerateKeyPair creates a private key of keyType in OpenSSH format, encrypted with passphrase unless it is
+// empty, and returns it with its public key in authorized_keys format. RSA keys have 3072 bits and ECDSA keys
+// use P-256 like the defaults of ssh-keygen.
+func GenerateKeyPair(keyType string, comment string, passphrase string) ([]byte, []byte, error) {
+	var key crypto.Signer
+	var err error
+	switch keyType {
+	case "ed25519":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "rsa":
+		key, err = rsa.GenerateKey(rand.Reader, 3072)
+	case "ecdsa":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type '%s'", keyType)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to generate %s key: %v", keyType, err)
+	}
+
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, comment)
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, comment, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to encode %s key: %v", keyType, err)
+	}
+
+	publicKey, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to encode %s public key: %v", keyType, err)
+	}
+	public := ssh.MarshalAuthorizedKey(publicKey)
+	if comment != "" {
+		public = append(public[:len(public)-1], []byte(" "+comment+"\n")...)
+	}
+
+	return pem.EncodeToMemory(block), public, nil
+}
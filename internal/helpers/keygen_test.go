@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateKeyPair(t *testing.T) {
+	tests := []struct {
+		keyType    string
+		passphrase string
+		algorithm  string
+	}{
+		{"ed25519", "", ssh.KeyAlgoED25519},
+		{"ecdsa", "secret", ssh.KeyAlgoECDSA256},
+		{"rsa", "", ssh.KeyAlgoRSA},
+	}
+
+	for _, test := range tests {
+		t.Run(test.keyType, func(t *testing.T) {
+			private, public, err := GenerateKeyPair(test.keyType, "web01", test.passphrase)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			publicKey, comment, _, _, err := ssh.ParseAuthorizedKey(public)
+			if err != nil {
+				t.Fatalf("invalid public key %q: %v", public, err)
+			}
+			if publicKey.Type() != test.algorithm || comment != "web01" {
+				t.Errorf("got %s key with comment %q, want %s key with comment web01", publicKey.Type(), comment, test.algorithm)
+			}
+
+			var signer ssh.Signer
+			if test.passphrase == "" {
+				signer, err = ssh.ParsePrivateKey(private)
+			} else {
+				if _, err := ssh.ParsePrivateKey(private); err == nil {
+					t.Errorf("private key is not encrypted")
+				}
+				signer, err = ssh.ParsePrivateKeyWithPassphrase(private, []byte(test.passphrase))
+			}
+			if err != nil {
+				t.Fatalf("invalid private key: %v", err)
+			}
+			if got := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))); !strings.HasPrefix(string(public), got) {
+				t.Errorf("private key does not match public key")
+			}
+		})
+	}
+
+	if _, _, err := GenerateKeyPair("dsa", "web01", ""); err == nil {
+		t.Errorf("expected an error for unsupported key type")
+	}
+}
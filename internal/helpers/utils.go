@@ -1,5 +1,11 @@
 package helpers
 
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
 func SliceContains(stringSlice []string, search string) bool {
 	for _, v := range stringSlice {
 		if v == search {
@@ -9,3 +15,62 @@ func SliceContains(stringSlice []string, search string) bool {
 
 	return false
 }
+
+func UniqueStrings(stringSlice []string) []string {
+	unique := make([]string, 0, len(stringSlice))
+	for _, v := range stringSlice {
+		if !SliceContains(unique, v) {
+			unique = append(unique, v)
+		}
+	}
+
+	return unique
+}
+
+// SplitFields splits line at whitespace like a shell does, keeping whitespace within single or double quotes
+// and after a backslash, e.g. `set web ProxyCommand "ssh -W %h:%p bastion"` yields 4 fields
+func SplitFields(line string) ([]string, error) {
+	fields := make([]string, 0, 8)
+	var field strings.Builder
+	inField, escaped := false, false
+	var quote rune
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inField = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				field.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inField = r, true
+		case unicode.IsSpace(r):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	return fields, nil
+}
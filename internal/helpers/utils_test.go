@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUniqueStrings(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   []string
+	}{
+		{values: []string{}, want: []string{}},
+		{values: []string{"web01"}, want: []string{"web01"}},
+		{values: []string{"web01", "db01", "web01", "web01"}, want: []string{"web01", "db01"}},
+		{values: []string{"db01", "web01", "db01"}, want: []string{"db01", "web01"}},
+		{values: []string{"web01", "Web01"}, want: []string{"web01", "Web01"}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.values), func(t *testing.T) {
+			if got := UniqueStrings(test.values); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+		err  bool
+	}{
+		{line: "", want: []string{}},
+		{line: "   \t ", want: []string{}},
+		{line: "add 10.0.0.1 web01", want: []string{"add", "10.0.0.1", "web01"}},
+		{line: "  add \t10.0.0.1   web01  ", want: []string{"add", "10.0.0.1", "web01"}},
+		{line: `set web ProxyCommand "ssh -W %h:%p bastion"`, want: []string{"set", "web", "ProxyCommand", "ssh -W %h:%p bastion"}},
+		{line: `set web ProxyCommand 'ssh -W %h:%p bastion'`, want: []string{"set", "web", "ProxyCommand", "ssh -W %h:%p bastion"}},
+		{line: `a"b c"d`, want: []string{"ab cd"}},
+		{line: `a "" b`, want: []string{"a", "", "b"}},
+		{line: `a '' b`, want: []string{"a", "", "b"}},
+		{line: `"it's" 'say "hi"'`, want: []string{"it's", `say "hi"`}},
+		{line: `a\ b c`, want: []string{"a b", "c"}},
+		{line: `\"a\"`, want: []string{`"a"`}},
+		{line: `"a \" b"`, want: []string{`a " b`}},
+		{line: `'a \ b'`, want: []string{`a \ b`}},
+		{line: `\\`, want: []string{`\`}},
+		{line: `"unterminated`, err: true},
+		{line: `'unterminated`, err: true},
+		{line: `trailing\`, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			got, err := SplitFields(test.line)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
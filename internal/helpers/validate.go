@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"net/netip"
+	"regexp"
+	"strconv"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+var hostnameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?(\.[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?)*\.?$`)
+
+var numericRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*\.?$`)
+
+// IsValidHostname reports whether hostname is a valid host name. Names of digits and dots only are rejected as
+// resolvers take them for IP addresses, e.g. 10.0.1.256 expanded from a range.
+func IsValidHostname(hostname string) bool {
+	return len(hostname) <= 253 && hostnameRegexp.MatchString(hostname) && !numericRegexp.MatchString(hostname)
+}
+
+// IsValidAddress reports whether address is an IP address, including IPv6 with zone like fe80::1%lo0, or a domain
+func IsValidAddress(address string) bool {
+	_, err := netip.ParseAddr(address)
+
+	return err == nil || IsValidHostname(address)
+}
+
+func IsValidPort(port string) bool {
+	p, err := strconv.Atoi(port)
+
+	return err == nil && p > 0 && p < 65536
+}
+
+func IsValidTag(tag string) bool {
+	return nameRegexp.MatchString(tag)
+}
+
+func IsValidOwner(owner string) bool {
+	return nameRegexp.MatchString(owner)
+}
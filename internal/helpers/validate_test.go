@@ -0,0 +1,109 @@
+package helpers
+
+import "testing"
+
+func TestIsValidHostname(t *testing.T) {
+	tests := []struct {
+		hostname string
+		valid    bool
+	}{
+		{"web01", true},
+		{"web01.lab.example.com", true},
+		{"node-0", true},
+		{"_service", true},
+		{"3com.example", true},
+		{"web01.", true},
+		{"-web", false},
+		{"web-", false},
+		{"web..lab", false},
+		{"web 01", false},
+		{"", false},
+		{"10.0.1.256", false},
+		{"10.0.1.1", false},
+		{"1234", false},
+		{"::1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.hostname, func(t *testing.T) {
+			if got := IsValidHostname(test.hostname); got != test.valid {
+				t.Errorf("IsValidHostname(%q) = %v, want %v", test.hostname, got, test.valid)
+			}
+		})
+	}
+}
+
+func TestIsValidAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		valid   bool
+	}{
+		{"10.0.1.1", true},
+		{"10.0.1.255", true},
+		{"10.0.1.256", false},
+		{"10.0.1", false},
+		{"::1", true},
+		{"2001:db8::1", true},
+		{"fe80::1%lo0", true},
+		{"fe80::1%", false},
+		{"example.com", true},
+		{"bastion", true},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			if got := IsValidAddress(test.address); got != test.valid {
+				t.Errorf("IsValidAddress(%q) = %v, want %v", test.address, got, test.valid)
+			}
+		})
+	}
+}
+
+func TestIsValidPort(t *testing.T) {
+	tests := []struct {
+		port  string
+		valid bool
+	}{
+		{"22", true},
+		{"1", true},
+		{"65535", true},
+		{"0", false},
+		{"65536", false},
+		{"-22", false},
+		{"ssh", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.port, func(t *testing.T) {
+			if got := IsValidPort(test.port); got != test.valid {
+				t.Errorf("IsValidPort(%q) = %v, want %v", test.port, got, test.valid)
+			}
+		})
+	}
+}
+
+func TestIsValidTag(t *testing.T) {
+	tests := []struct {
+		tag   string
+		valid bool
+	}{
+		{"k8s", true},
+		{"lab-01.v2", true},
+		{"Team_A", true},
+		{"", false},
+		{"-lab", false},
+		{"lab,k8s", false},
+		{"lab=1", false},
+		{"lab 1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			if got := IsValidTag(test.tag); got != test.valid {
+				t.Errorf("IsValidTag(%q) = %v, want %v", test.tag, got, test.valid)
+			}
+		})
+	}
+}
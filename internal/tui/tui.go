@@ -0,0 +1,564 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/martinnirtl/hosts-cli/internal/diff"
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"github.com/martinnirtl/hosts-cli/pkg/files"
+)
+
+type mode int
+
+const (
+	listMode mode = iota
+	filterMode
+	editMode
+	previewMode
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	cursorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true)
+	disabledStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	kindStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	helpStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	statusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	addedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	removedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// item is either a hosts file entry or a ssh config block
+type item struct {
+	host  *files.Host
+	block *files.HostBlock
+}
+
+func (i item) disabled() bool {
+	if i.host != nil {
+		return i.host.Disabled()
+	}
+
+	return i.block.Disabled
+}
+
+func (i item) String() string {
+	if i.host != nil {
+		return kindStyle.Render("hosts ") + fmt.Sprintf("%-16s %s", i.host.Address(), strings.Join(i.host.Aliases(), " "))
+	}
+
+	hostname, _ := i.block.GetProp("HostName")
+	return kindStyle.Render("ssh   ") + fmt.Sprintf("%-16s %s %s", hostname, i.block.Kind, strings.Join(i.block.Hosts, " "))
+}
+
+type model struct {
+	hosts     *files.Hosts
+	sshConfig *files.SSHConfig
+
+	// serialized files as loaded, so previews only show edits and not formatting applied on write
+	hostsBefore     string
+	sshConfigBefore string
+
+	items   []item
+	visible []int
+	cursor  int
+	offset  int
+
+	mode       mode
+	filter     textinput.Model
+	form       []textinput.Model
+	formLabels []string
+	formFocus  int
+	preview    viewport.Model
+
+	width       int
+	height      int
+	status      string
+	dirty       bool
+	confirmQuit bool
+	save        bool
+}
+
+// Run starts the full-screen UI and reports whether the user chose to save the changes
+func Run(hosts *files.Hosts, sshConfig *files.SSHConfig) (bool, error) {
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "filter"
+
+	m := &model{
+		hosts:     hosts,
+		sshConfig: sshConfig,
+		filter:    filter,
+		preview:   viewport.New(0, 0),
+	}
+	if hosts != nil {
+		m.hostsBefore = hosts.String()
+	}
+	m.sshConfigBefore = sshConfig.String()
+	m.loadItems()
+
+	result, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return false, err
+	}
+
+	return result.(*model).save, nil
+}
+
+func (m *model) loadItems() {
+	m.items = make([]item, 0, 10)
+	if m.hosts != nil {
+		for _, host := range m.hosts.Entries() {
+			m.items = append(m.items, item{host: host})
+		}
+	}
+	for _, block := range m.sshConfig.Blocks() {
+		m.items = append(m.items, item{block: block})
+	}
+
+	m.applyFilter()
+}
+
+func (m *model) applyFilter() {
+	query := strings.ToLower(m.filter.Value())
+
+	m.visible = make([]int, 0, len(m.items))
+	for i, it := range m.items {
+		if query == "" || strings.Contains(strings.ToLower(it.String()), query) {
+			m.visible = append(m.visible, i)
+		}
+	}
+
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m *model) selected() (item, bool) {
+	if len(m.visible) == 0 {
+		return item{}, false
+	}
+
+	return m.items[m.visible[m.cursor]], true
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.preview.Width = msg.Width
+		m.preview.Height = msg.Height - 4
+
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		switch m.mode {
+		case filterMode:
+			return m.updateFilter(msg)
+		case editMode:
+			return m.updateForm(msg)
+		case previewMode:
+			return m.updatePreview(msg)
+		default:
+			return m.updateList(msg)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key != "q" {
+		m.confirmQuit = false
+	}
+	m.status = ""
+
+	switch key {
+	case "q", "esc":
+		if m.dirty && !m.confirmQuit {
+			m.confirmQuit = true
+			m.status = "Unsaved changes! Press q again to discard them or s to save."
+
+			return m, nil
+		}
+
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.visible)-1 {
+			m.cursor++
+		}
+
+	case "home", "g":
+		m.cursor = 0
+
+	case "end", "G":
+		m.cursor = len(m.visible) - 1
+
+	case "/":
+		m.mode = filterMode
+		m.filter.Focus()
+
+		return m, textinput.Blink
+
+	case " ", "t":
+		if it, ok := m.selected(); ok {
+			if it.host != nil {
+				it.host.SetDisabled(!it.host.Disabled())
+			} else {
+				it.block.Disabled = !it.block.Disabled
+			}
+			m.dirty = true
+		}
+
+	case "d", "delete":
+		if it, ok := m.selected(); ok {
+			if it.host != nil {
+				m.hosts.RemoveEntry(it.host)
+			} else {
+				m.sshConfig.RemoveBlock(it.block)
+			}
+			m.dirty = true
+			m.status = "Entry deleted."
+			m.loadItems()
+		}
+
+	case "e", "enter":
+		if it, ok := m.selected(); ok {
+			m.openForm(it)
+
+			return m, textinput.Blink
+		}
+
+	case "p", "s":
+		m.openPreview()
+	}
+
+	return m, nil
+}
+
+func (m *model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.filter.SetValue("")
+		fallthrough
+	case "enter":
+		m.filter.Blur()
+		m.mode = listMode
+		m.applyFilter()
+
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.cursor = 0
+	m.applyFilter()
+
+	return m, cmd
+}
+
+func (m *model) openForm(it item) {
+	m.form = make([]textinput.Model, 0, 4)
+	m.formLabels = make([]string, 0, 4)
+
+	addInput := func(label string, value string) {
+		input := textinput.New()
+		input.Prompt = ""
+		input.SetValue(value)
+		m.form = append(m.form, input)
+		m.formLabels = append(m.formLabels, label)
+	}
+
+	if it.host != nil {
+		addInput("Address", it.host.Address())
+		addInput("Aliases", strings.Join(it.host.Aliases(), " "))
+	} else {
+		addInput(it.block.Kind, strings.Join(it.block.Hosts, " "))
+		for _, prop := range it.block.Props {
+			addInput("Property", prop.Kind+" "+prop.Value)
+		}
+		addInput("Property", "") // allows adding a new property
+	}
+
+	m.formFocus = 0
+	m.form[0].Focus()
+	m.mode = editMode
+	m.status = ""
+}
+
+func (m *model) focusField(index int) {
+	m.form[m.formFocus].Blur()
+	m.formFocus = (index + len(m.form)) % len(m.form)
+	m.form[m.formFocus].Focus()
+}
+
+func (m *model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = listMode
+		m.status = "Edit cancelled."
+
+		return m, nil
+
+	case "tab", "down":
+		m.focusField(m.formFocus + 1)
+
+		return m, nil
+
+	case "shift+tab", "up":
+		m.focusField(m.formFocus - 1)
+
+		return m, nil
+
+	case "enter":
+		if m.formFocus < len(m.form)-1 {
+			m.focusField(m.formFocus + 1)
+
+			return m, nil
+		}
+
+		if err := m.submitForm(); err != nil {
+			m.status = err.Error()
+
+			return m, nil
+		}
+
+		m.mode = listMode
+		m.dirty = true
+		m.status = "Entry updated."
+		m.applyFilter()
+
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.form[m.formFocus], cmd = m.form[m.formFocus].Update(msg)
+
+	return m, cmd
+}
+
+func (m *model) submitForm() error {
+	it, ok := m.selected()
+	if !ok {
+		return nil
+	}
+
+	if it.host != nil {
+		address := strings.TrimSpace(m.form[0].Value())
+		if !helpers.IsValidAddress(address) {
+			return fmt.Errorf("'%s' is not a valid IP address or domain", address)
+		}
+		aliases := strings.Fields(m.form[1].Value())
+		if len(aliases) == 0 {
+			return fmt.Errorf("At least one alias is required")
+		}
+		for _, alias := range aliases {
+			if !helpers.IsValidHostname(alias) {
+				return fmt.Errorf("'%s' is not a valid host name", alias)
+			}
+		}
+
+		it.host.SetAddress(address)
+		it.host.SetAliases(aliases)
+
+		return nil
+	}
+
+	hosts := strings.Fields(m.form[0].Value())
+	if len(hosts) == 0 {
+		return fmt.Errorf("At least one host pattern is required")
+	}
+
+	props := make([]*files.HostBlockProp, 0, len(m.form)-1)
+	for _, input := range m.form[1:] {
+		fields := strings.Fields(input.Value())
+		if len(fields) == 0 {
+			continue // empty properties get removed
+		}
+		if len(fields) == 1 {
+			return fmt.Errorf("Property '%s' requires a value", fields[0])
+		}
+
+		keyword, ok := files.CanonicalKeyword(fields[0])
+		if !ok {
+			return fmt.Errorf("Unknown ssh_config keyword '%s'", fields[0])
+		}
+		switch keyword {
+		case "Host", "Match", "Include":
+			return fmt.Errorf("Keyword '%s' can not be used as property", keyword)
+		}
+		value := strings.Join(fields[1:], " ")
+		if err := files.ValidateSSHConfig(keyword + " " + value); err != nil {
+			return fmt.Errorf("Invalid value for %s: %v", keyword, strings.TrimPrefix(err.Error(), "line 1: "))
+		}
+
+		props = append(props, &files.HostBlockProp{Kind: keyword, Value: value})
+	}
+
+	it.block.Hosts = hosts
+	it.block.Props = props
+
+	return nil
+}
+
+func (m *model) openPreview() {
+	var content strings.Builder
+	if m.hosts != nil {
+		content.WriteString(fileDiff(m.hosts.Filepath(), m.hostsBefore, m.hosts.String()))
+	}
+	content.WriteString(fileDiff(m.sshConfig.Filepath(), m.sshConfigBefore, m.sshConfig.String()))
+
+	if content.Len() == 0 {
+		content.WriteString("No changes.\n")
+	}
+
+	m.preview.SetContent(content.String())
+	m.preview.GotoTop()
+	m.mode = previewMode
+	m.status = ""
+}
+
+func fileDiff(path string, original string, updated string) string {
+	unified := diff.Unified(path, path, original, updated, 3)
+	lines := strings.Split(unified, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = titleStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = addedStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = removedStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = kindStyle.Render(line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m *model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y":
+		m.save = true
+
+		return m, tea.Quit
+
+	case "esc", "q", "n":
+		m.mode = listMode
+
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.preview, cmd = m.preview.Update(msg)
+
+	return m, cmd
+}
+
+func (m *model) View() string {
+	switch m.mode {
+	case previewMode:
+		return titleStyle.Render("Pending changes") + "\n\n" +
+			m.preview.View() + "\n" +
+			helpStyle.Render("↑/↓ scroll • y save • esc back")
+
+	case editMode:
+		return m.viewForm()
+	}
+
+	return m.viewList()
+}
+
+func (m *model) viewList() string {
+	var view strings.Builder
+
+	title := "Hosts"
+	if m.dirty {
+		title += " (modified)"
+	}
+	view.WriteString(titleStyle.Render(title) + "\n")
+
+	if m.mode == filterMode || m.filter.Value() != "" {
+		view.WriteString(m.filter.View() + "\n")
+	} else {
+		view.WriteString("\n")
+	}
+
+	rows := m.height - 5
+	if rows < 1 {
+		rows = len(m.visible)
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+
+	for i := m.offset; i < len(m.visible) && i < m.offset+rows; i++ {
+		it := m.items[m.visible[i]]
+
+		state := "● "
+		line := it.String()
+		if it.disabled() {
+			state = "○ "
+			line = disabledStyle.Render(line)
+		}
+
+		if i == m.cursor {
+			view.WriteString(cursorStyle.Render("> ") + state + line + "\n")
+		} else {
+			view.WriteString("  " + state + line + "\n")
+		}
+	}
+	if len(m.visible) == 0 {
+		view.WriteString(helpStyle.Render("  No entries found.") + "\n")
+	}
+
+	view.WriteString("\n" + statusStyle.Render(m.status) + "\n")
+	view.WriteString(helpStyle.Render("↑/↓ move • / filter • e edit • space toggle • d delete • p preview • s save • q quit"))
+
+	return view.String()
+}
+
+func (m *model) viewForm() string {
+	var view strings.Builder
+	view.WriteString(titleStyle.Render("Edit entry") + "\n\n")
+
+	for i, input := range m.form {
+		label := fmt.Sprintf("%-10s", m.formLabels[i])
+		if i == m.formFocus {
+			label = cursorStyle.Render(label)
+		}
+		view.WriteString(label + " " + input.View() + "\n")
+	}
+
+	view.WriteString("\n" + statusStyle.Render(m.status) + "\n")
+	view.WriteString(helpStyle.Render("tab/↑/↓ switch field • enter next/apply • esc cancel • clear a property to remove it"))
+
+	return view.String()
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/martinnirtl/hosts-cli/internal/helpers"
 )
@@ -29,27 +30,147 @@ type Hosts struct {
 }
 
 type Host struct {
-	address string
-	aliases []string
-	comment string
+	address  string
+	aliases  []string
+	comment  string
+	meta     Metadata
+	disabled bool
 }
 
 func (host *Host) String() string {
 	output := host.address + " " + strings.Join(host.aliases, " ")
 	if host.comment != "" {
-		output = output + " " + host.comment
+		output = output + " # " + host.comment
+	}
+	if len(host.meta) > 0 {
+		output = output + " # " + host.meta.String()
+	}
+	if host.disabled {
+		output = disabledMarker + " " + output
 	}
 
 	return output
 }
 
+func (host *Host) Address() string {
+	return host.address
+}
+
+func (host *Host) SetAddress(address string) {
+	host.address = address
+}
+
+func (host *Host) Aliases() []string {
+	return host.aliases
+}
+
+func (host *Host) SetAliases(aliases []string) {
+	host.aliases = aliases
+}
+
+func (host *Host) GetMeta(key string) string {
+	return getMeta(host.meta, key)
+}
+
+func (host *Host) SetMeta(key string, value string) {
+	host.meta = setMeta(host.meta, key, value)
+}
+
+// ClearMeta removes all metadata of the entry, e.g. before storing it in a profile
+func (host *Host) ClearMeta() {
+	host.meta = nil
+}
+
+// Required reports whether the entry maps localhost or broadcasthost, which must never be removed
+func (host *Host) Required() bool {
+	for _, alias := range host.aliases {
+		if helpers.SliceContains(requiredAliases, alias) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Managed reports whether the entry was created by the CLI
+func (host *Host) Managed() bool {
+	return getMeta(host.meta, managedMetaKey) == "true"
+}
+
+func (host *Host) SetManaged(managed bool) {
+	host.meta = setManaged(host.meta, managed)
+}
+
+// Expires returns the expiry time set by 'hosts add --ttl' if any
+func (host *Host) Expires() (time.Time, bool) {
+	return getExpires(host.meta)
+}
+
+func (host *Host) SetExpires(expires time.Time) {
+	host.meta = setExpires(host.meta, expires)
+}
+
+func (host *Host) Expired(now time.Time) bool {
+	return isExpired(host.meta, now)
+}
+
+func (host *Host) Tags() []string {
+	return getList(host.meta, tagsMetaKey)
+}
+
+func (host *Host) HasTag(tag string) bool {
+	return helpers.SliceContains(host.Tags(), tag)
+}
+
+func (host *Host) AddTags(tags ...string) {
+	host.meta = addToList(host.meta, tagsMetaKey, tags)
+}
+
+// Owners returns the owners sharing the entry as registered by 'hosts add --owner'
+func (host *Host) Owners() []string {
+	return getOwners(host.meta)
+}
+
+func (host *Host) HasOwner(owner string) bool {
+	return helpers.SliceContains(host.Owners(), owner)
+}
+
+// AddOwner registers owner, which is released by 'hosts gc' after expires unless it is zero
+func (host *Host) AddOwner(owner string, expires time.Time) {
+	host.meta = addOwner(host.meta, owner, expires)
+}
+
+// RemoveOwner releases the entry by owner and returns the number of remaining owners
+func (host *Host) RemoveOwner(owner string) int {
+	host.meta = removeOwner(host.meta, owner)
+
+	return len(host.Owners())
+}
+
+// ReleaseExpiredOwners releases the entry by all owners whose expiry has passed and returns them
+func (host *Host) ReleaseExpiredOwners(now time.Time) []string {
+	var released []string
+	host.meta, released = releaseExpiredOwners(host.meta, now)
+
+	return released
+}
+
+func (host *Host) Disabled() bool {
+	return host.disabled
+}
+
+func (host *Host) SetDisabled(disabled bool) {
+	host.disabled = disabled
+}
+
 func (hosts *Hosts) String() string {
-	sort.Slice(hosts.entries, func(i, j int) bool {
-		if hosts.entries[i].address == "127.0.0.1" || hosts.entries[i].address == "255.255.255.255" || hosts.entries[i].address == "::1" {
-			return true // always print on top
+	sort.SliceStable(hosts.entries, func(i, j int) bool {
+		topI, topJ := isTopAddress(hosts.entries[i].address), isTopAddress(hosts.entries[j].address)
+		if topI || topJ {
+			return topI && !topJ // always print on top
 		}
 
-		return hosts.entries[j].address > hosts.entries[i].address
+		return hosts.entries[i].address < hosts.entries[j].address
 	})
 
 	output := make([]string, len(hosts.entries))
@@ -60,6 +181,14 @@ func (hosts *Hosts) String() string {
 	return strings.Join(output, "\n") + "\n"
 }
 
+func isTopAddress(address string) bool {
+	return address == "127.0.0.1" || address == "255.255.255.255" || address == "::1"
+}
+
+func (hosts *Hosts) Bytes() []byte {
+	return []byte(hosts.String() + "\n")
+}
+
 func (hosts *Hosts) Read() error {
 	file, err := os.Open(hosts.filepath)
 	if err != nil {
@@ -67,17 +196,33 @@ func (hosts *Hosts) Read() error {
 	}
 	defer file.Close()
 
-	rd := bufio.NewReader(file)
+	// invalid lines are kept as they are, the file may have been written by hand or other tools
+	entries, err := parseHosts(file)
+	if _, invalid := err.(ParseErrors); err != nil && !invalid {
+		return err
+	}
+	hosts.entries = append(hosts.entries, entries...)
+
+	return nil
+}
+
+// parseHosts returns all entries of r and ParseErrors for all invalid lines, which are parsed nevertheless
+func parseHosts(r io.Reader) ([]*Host, error) {
+	entries := make([]*Host, 0, 10)
+	errs := make(ParseErrors, 0)
+
+	rd := bufio.NewReader(r)
 
 	var currentHost *Host
-	for {
+	for lineNumber := 1; ; lineNumber++ {
 		line, err := rd.ReadString('\n')
 		if err != nil && err != io.EOF {
-			return fmt.Errorf("Failed reading file: %v", err)
+			return nil, fmt.Errorf("Failed reading file: %v", err)
 		} else if err == io.EOF && len(line) == 0 {
 			break
 		}
 
+		line, disabled := cutDisabledMarker(line)
 		entry, comment, _ := strings.Cut(line, "#")
 
 		fields := strings.Fields(entry)
@@ -85,23 +230,61 @@ func (hosts *Hosts) Read() error {
 			continue // skip empty lines
 		}
 
-		currentHost = &Host{address: fields[0], aliases: fields[1:], comment: comment}
-		hosts.entries = append(hosts.entries, currentHost)
+		if !helpers.IsValidAddress(fields[0]) {
+			errs = append(errs, &ParseError{Line: lineNumber, Message: fmt.Sprintf("'%s' is not a valid IP address or domain", fields[0])})
+		}
+		if len(fields) == 1 {
+			errs = append(errs, &ParseError{Line: lineNumber, Message: fmt.Sprintf("missing host names for address '%s'", fields[0])})
+		}
+		for _, alias := range fields[1:] {
+			if !helpers.IsValidHostname(alias) {
+				errs = append(errs, &ParseError{Line: lineNumber, Message: fmt.Sprintf("'%s' is not a valid host name", alias)})
+			}
+		}
+
+		comment, meta := cutMetadata(comment)
+
+		currentHost = &Host{address: fields[0], aliases: fields[1:], comment: comment, meta: meta, disabled: disabled}
+		entries = append(entries, currentHost)
 	}
 
-	return nil
+	if len(errs) > 0 {
+		return entries, errs
+	}
+
+	return entries, nil
+}
+
+func (hosts *Hosts) Filepath() string {
+	return hosts.filepath
+}
+
+func (hosts *Hosts) Entries() []*Host {
+	return hosts.entries
+}
+
+// FindEntries returns all entries mapping the given alias
+func (hosts *Hosts) FindEntries(alias string) []*Host {
+	found := make([]*Host, 0, 1)
+	for _, entry := range hosts.entries {
+		if helpers.SliceContains(entry.aliases, alias) {
+			found = append(found, entry)
+		}
+	}
+
+	return found
 }
 
 func (hosts *Hosts) ListHosts() [][]string {
 	list := make([][]string, len(hosts.entries))
-	for _, entry := range hosts.entries {
-		list = append(list, entry.aliases)
+	for i, entry := range hosts.entries {
+		list[i] = entry.aliases
 	}
 
 	return list
 }
 
-func (h *Hosts) AddHost(address string, aliases []string) error {
+func (h *Hosts) AddHost(address string, aliases []string) (*Host, error) {
 	host := &Host{
 		address: address,
 		aliases: aliases,
@@ -112,7 +295,7 @@ func (h *Hosts) AddHost(address string, aliases []string) error {
 
 	h.entries = append(h.entries, host)
 
-	return nil
+	return host, nil
 }
 
 func (h *Hosts) RemoveHosts(hosts []string) []*Host {
@@ -149,6 +332,197 @@ func (h *Hosts) RemoveHosts(hosts []string) []*Host {
 	return removed
 }
 
+// RemoveEntry removes exactly the given entry, leaving other entries with the same aliases untouched
+func (hosts *Hosts) RemoveEntry(host *Host) bool {
+	for i, entry := range hosts.entries {
+		if entry == host {
+			hosts.entries = append(hosts.entries[:i], hosts.entries[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetDisabled switches all entries of alias off or on and returns the number of changed entries. Entries
+// shared with other aliases are split, so that those stay as they are.
+func (hosts *Hosts) SetDisabled(alias string, disabled bool) int {
+	changed := 0
+	for _, entry := range hosts.FindEntries(alias) {
+		if entry.disabled != disabled {
+			hosts.SplitAlias(entry, alias).disabled = disabled
+			changed++
+		}
+	}
+
+	return changed
+}
+
+// RemoveEntriesFunc removes all entries matching and returns them
+func (hosts *Hosts) RemoveEntriesFunc(match func(host *Host) bool) []*Host {
+	kept := make([]*Host, 0, len(hosts.entries))
+	removed := make([]*Host, 0, 10)
+	for _, entry := range hosts.entries {
+		if match(entry) {
+			removed = append(removed, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	hosts.entries = kept
+
+	return removed
+}
+
+// RenameAlias replaces alias by newAlias in all entries and returns the number of changed entries
+func (hosts *Hosts) RenameAlias(alias string, newAlias string) int {
+	renamed := 0
+	for _, entry := range hosts.entries {
+		if !helpers.SliceContains(entry.aliases, alias) {
+			continue
+		}
+
+		for i, a := range entry.aliases {
+			if a == alias {
+				entry.aliases[i] = newAlias
+			}
+		}
+		entry.aliases = helpers.UniqueStrings(entry.aliases)
+		renamed++
+	}
+
+	return renamed
+}
+
+// SetAddress maps alias to address. Entries only holding alias get updated in place, otherwise
+// alias is moved to an entry of address or a new one. If an entry of address holds alias already, alias
+// is removed from all others. Returns the number of changed entries.
+func (hosts *Hosts) SetAddress(alias string, address string) int {
+	var target *Host
+	for _, entry := range hosts.entries {
+		if entry.address == address && !entry.disabled && helpers.SliceContains(entry.aliases, alias) {
+			target = entry
+			break
+		}
+	}
+
+	changed := 0
+	moved := false
+	entries := make([]*Host, 0, len(hosts.entries))
+	for _, entry := range hosts.entries {
+		if !helpers.SliceContains(entry.aliases, alias) || entry.address == address {
+			entries = append(entries, entry)
+			continue
+		}
+		changed++
+
+		if len(entry.aliases) == 1 {
+			if target == nil {
+				entry.address = address
+				target = entry
+				entries = append(entries, entry)
+			}
+			continue
+		}
+
+		aliases := make([]string, 0, len(entry.aliases)-1)
+		for _, a := range entry.aliases {
+			if a != alias {
+				aliases = append(aliases, a)
+			}
+		}
+		entry.aliases = aliases
+		entries = append(entries, entry)
+		moved = true
+	}
+	hosts.entries = entries
+
+	if moved && target == nil {
+		for _, entry := range hosts.entries {
+			if entry.address == address && !entry.disabled {
+				entry.aliases = append(entry.aliases, alias)
+
+				return changed
+			}
+		}
+
+		hosts.entries = append(hosts.entries, &Host{address: address, aliases: []string{alias}})
+	}
+
+	return changed
+}
+
+// SplitAlias moves alias out of an entry shared with other aliases into a copy of the entry right after it
+// and returns the copy. Entries only holding alias are returned as they are.
+func (hosts *Hosts) SplitAlias(entry *Host, alias string) *Host {
+	others := make([]string, 0, len(entry.aliases))
+	for _, a := range entry.aliases {
+		if a != alias {
+			others = append(others, a)
+		}
+	}
+	if len(others) == 0 {
+		return entry
+	}
+
+	split := &Host{
+		address:  entry.address,
+		aliases:  []string{alias},
+		comment:  entry.comment,
+		meta:     entry.meta.clone(),
+		disabled: entry.disabled,
+	}
+	entry.aliases = others
+
+	for i, e := range hosts.entries {
+		if e == entry {
+			hosts.entries = append(hosts.entries[:i+1], append([]*Host{split}, hosts.entries[i+1:]...)...)
+			break
+		}
+	}
+
+	return split
+}
+
+// ReplaceEntries puts replacements at the position of the first old entry and drops the remaining old entries
+func (hosts *Hosts) ReplaceEntries(old []*Host, replacements []*Host) {
+	position := len(hosts.entries)
+	if len(old) > 0 {
+		for i, entry := range hosts.entries {
+			if entry == old[0] {
+				position = i
+				break
+			}
+		}
+	}
+
+	updated := make([]*Host, 0, len(hosts.entries)+len(replacements))
+	for i, entry := range hosts.entries {
+		if i == position {
+			updated = append(updated, replacements...)
+		}
+		if !containsHost(old, entry) {
+			updated = append(updated, entry)
+		}
+	}
+	if position == len(hosts.entries) {
+		updated = append(updated, replacements...)
+	}
+
+	hosts.entries = updated
+}
+
+func containsHost(entries []*Host, host *Host) bool {
+	for _, entry := range entries {
+		if entry == host {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (hosts *Hosts) Write() error {
 	file, err := os.OpenFile(hosts.filepath, os.O_RDWR, 0644)
 	if err != nil {
@@ -161,7 +535,7 @@ func (hosts *Hosts) Write() error {
 		return fmt.Errorf("Failed writing file '%s': %v", file.Name(), err)
 	}
 
-	_, err = file.WriteAt([]byte(hosts.String()+"\n"), 0)
+	_, err = file.WriteAt(hosts.Bytes(), 0)
 	if err != nil {
 		return fmt.Errorf("Failed writing file '%s': %v", file.Name(), err)
 	}
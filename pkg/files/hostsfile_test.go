@@ -0,0 +1,222 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func testHosts(t *testing.T, content string) *Hosts {
+	t.Helper()
+
+	entries, err := parseHosts(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parsing hosts: %v", err)
+	}
+
+	return &Hosts{entries: entries}
+}
+
+func TestHostString(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"plain", "10.0.0.1   web01\tweb01.lab", "10.0.0.1 web01 web01.lab"},
+		{"comment", "10.0.0.1 web01 # primary", "10.0.0.1 web01 # primary"},
+		{"comment without space", "10.0.0.1 web01 #primary", "10.0.0.1 web01 # primary"},
+		{"metadata", "10.0.0.1 web01 # primary # hosts-cli: managed=true", "10.0.0.1 web01 # primary # hosts-cli: managed=true"},
+		{"disabled", "#[disabled] 10.0.0.1 web01 # primary", "#[disabled] 10.0.0.1 web01 # primary"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hosts := testHosts(t, test.line+"\n")
+			if len(hosts.entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(hosts.entries))
+			}
+			if got := hosts.entries[0].String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHostsRenameAlias(t *testing.T) {
+	hosts := testHosts(t, "10.0.0.1 web01 web01.lab\n10.0.0.2 db01 web01\n10.0.0.3 web03 web01\n10.0.0.4 web02\n")
+
+	if got := hosts.RenameAlias("web01", "web03"); got != 3 {
+		t.Errorf("got %d renamed entries, want 3", got)
+	}
+
+	want := "10.0.0.1 web03 web01.lab\n10.0.0.2 db01 web03\n10.0.0.3 web03\n10.0.0.4 web02"
+	if got := strings.Join(hostStrings(hosts.entries), "\n"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHostsSetAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   string
+		alias   string
+		address string
+		want    string
+		changed int
+	}{
+		{
+			name:    "single alias in place",
+			hosts:   "10.0.0.1 web01 # hosts-cli: managed=true\n",
+			alias:   "web01",
+			address: "10.0.0.2",
+			want:    "10.0.0.2 web01 # hosts-cli: managed=true\n",
+			changed: 1,
+		},
+		{
+			name:    "shared line keeps other alias",
+			hosts:   "10.0.0.1 web01 web02\n",
+			alias:   "web01",
+			address: "10.0.0.2",
+			want:    "10.0.0.1 web02\n10.0.0.2 web01\n",
+			changed: 1,
+		},
+		{
+			name:    "moves to existing line of address",
+			hosts:   "10.0.0.1 web01 web02\n10.0.0.2 db01\n",
+			alias:   "web01",
+			address: "10.0.0.2",
+			want:    "10.0.0.1 web02\n10.0.0.2 db01 web01\n",
+			changed: 1,
+		},
+		{
+			name:    "no duplicate of address already mapped",
+			hosts:   "10.0.0.1 web01\n10.0.0.2 web01\n",
+			alias:   "web01",
+			address: "10.0.0.2",
+			want:    "10.0.0.2 web01\n",
+			changed: 1,
+		},
+		{
+			name:    "no duplicate of two old lines",
+			hosts:   "10.0.0.1 web01\n10.0.0.3 web01\n",
+			alias:   "web01",
+			address: "10.0.0.2",
+			want:    "10.0.0.2 web01\n",
+			changed: 2,
+		},
+		{
+			name:    "unchanged",
+			hosts:   "10.0.0.2 web01\n",
+			alias:   "web01",
+			address: "10.0.0.2",
+			want:    "10.0.0.2 web01\n",
+			changed: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hosts := testHosts(t, test.hosts)
+
+			if changed := hosts.SetAddress(test.alias, test.address); changed != test.changed {
+				t.Errorf("changed %d entries, want %d", changed, test.changed)
+			}
+			if got := hosts.String(); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHostsSetDisabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    string
+		alias    string
+		disabled bool
+		want     string
+		changed  int
+	}{
+		{
+			name:     "own line",
+			hosts:    "10.0.0.1 web01\n",
+			alias:    "web01",
+			disabled: true,
+			want:     "#[disabled] 10.0.0.1 web01\n",
+			changed:  1,
+		},
+		{
+			name:     "shared line keeps other alias",
+			hosts:    "10.0.0.1 web01 web02 # hosts-cli: managed=true\n",
+			alias:    "web01",
+			disabled: true,
+			want:     "10.0.0.1 web02 # hosts-cli: managed=true\n#[disabled] 10.0.0.1 web01 # hosts-cli: managed=true\n",
+			changed:  1,
+		},
+		{
+			name:     "enable one alias of disabled line",
+			hosts:    "#[disabled] 10.0.0.1 web01 web02\n",
+			alias:    "web02",
+			disabled: false,
+			want:     "#[disabled] 10.0.0.1 web01\n10.0.0.1 web02\n",
+			changed:  1,
+		},
+		{
+			name:     "already disabled",
+			hosts:    "#[disabled] 10.0.0.1 web01 web02\n",
+			alias:    "web01",
+			disabled: true,
+			want:     "#[disabled] 10.0.0.1 web01 web02\n",
+			changed:  0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hosts := testHosts(t, test.hosts)
+
+			if changed := hosts.SetDisabled(test.alias, test.disabled); changed != test.changed {
+				t.Errorf("changed %d entries, want %d", changed, test.changed)
+			}
+			if got := hosts.String(); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHostManaged(t *testing.T) {
+	hosts := testHosts(t, "127.0.0.1 localhost\n10.0.0.1 web01 # hosts-cli: managed=true\n10.0.0.2 db01 # primary\n")
+
+	tests := []struct {
+		alias    string
+		managed  bool
+		required bool
+	}{
+		{alias: "localhost", managed: false, required: true},
+		{alias: "web01", managed: true, required: false},
+		{alias: "db01", managed: false, required: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.alias, func(t *testing.T) {
+			entry := hosts.FindEntries(test.alias)[0]
+			if got := entry.Managed(); got != test.managed {
+				t.Errorf("got managed %v, want %v", got, test.managed)
+			}
+			if got := entry.Required(); got != test.required {
+				t.Errorf("got required %v, want %v", got, test.required)
+			}
+		})
+	}
+
+	entry := hosts.FindEntries("db01")[0]
+	entry.SetManaged(true)
+	if got := entry.String(); got != "10.0.0.2 db01 # primary # hosts-cli: managed=true" {
+		t.Errorf("got %q", got)
+	}
+	entry.SetManaged(false)
+	if got := entry.String(); got != "10.0.0.2 db01 # primary" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,430 @@
+package files
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+)
+
+const hashedHostPrefix = "|1|"
+
+type KnownHosts struct {
+	filepath string
+	lines    []*KnownHost
+}
+
+// KnownHost is a single line of a known_hosts file. Comments and malformed lines are kept verbatim.
+type KnownHost struct {
+	Marker  string   // @cert-authority, @revoked or empty
+	Hosts   []string // host patterns or a single hashed host
+	KeyType string
+	Key     string
+	Comment string
+	raw     string
+}
+
+func (line *KnownHost) String() string {
+	if line.KeyType == "" {
+		return line.raw
+	}
+
+	fields := make([]string, 0, 5)
+	if line.Marker != "" {
+		fields = append(fields, line.Marker)
+	}
+	fields = append(fields, strings.Join(line.Hosts, ","), line.KeyType, line.Key)
+	if line.Comment != "" {
+		fields = append(fields, line.Comment)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// Hashed reports whether the host of the line is hashed (HashKnownHosts yes)
+func (line *KnownHost) Hashed() bool {
+	return len(line.Hosts) == 1 && strings.HasPrefix(line.Hosts[0], hashedHostPrefix)
+}
+
+// Matches reports whether the line holds a key for host, e.g. web01 or [web01]:2222
+func (line *KnownHost) Matches(host string) bool {
+	for _, pattern := range line.Hosts {
+		if strings.HasPrefix(pattern, hashedHostPrefix) {
+			if matchesHashedHost(pattern, host) {
+				return true
+			}
+		} else if pattern == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesHashedHost(hashed string, host string) bool {
+	parts := strings.Split(strings.TrimPrefix(hashed, hashedHostPrefix), "|")
+	if len(parts) != 2 {
+		return false
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(host))
+
+	return hmac.Equal(mac.Sum(nil), hash)
+}
+
+// HashHost hashes host the way ssh-keygen -H does
+func HashHost(host string) (string, error) {
+	salt := make([]byte, sha1.Size)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("Failed to generate salt: %v", err)
+	}
+
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(host))
+
+	return hashedHostPrefix + base64.StdEncoding.EncodeToString(salt) + "|" + base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// KnownHostsName returns host in known_hosts notation, i.e. [host]:port for non-default ports
+func KnownHostsName(host string, port string) string {
+	if port == "" || port == "22" {
+		return host
+	}
+
+	return fmt.Sprintf("[%s]:%s", host, port)
+}
+
+func (knownHosts *KnownHosts) String() string {
+	output := make([]string, len(knownHosts.lines))
+	for i, line := range knownHosts.lines {
+		output[i] = line.String()
+	}
+
+	return strings.Join(output, "\n")
+}
+
+func (knownHosts *KnownHosts) Bytes() []byte {
+	if len(knownHosts.lines) == 0 {
+		return []byte{}
+	}
+
+	return []byte(knownHosts.String() + "\n")
+}
+
+func (knownHosts *KnownHosts) Filepath() string {
+	return knownHosts.filepath
+}
+
+func (knownHosts *KnownHosts) Lines() []*KnownHost {
+	return knownHosts.lines
+}
+
+func (knownHosts *KnownHosts) Read() error {
+	file, err := os.Open(knownHosts.filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // ssh creates the file on first connect
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to open '%s': %v", knownHosts.filepath, err)
+	}
+	defer file.Close()
+
+	rd := bufio.NewReader(file)
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("Failed reading file: %v", err)
+		} else if err == io.EOF && len(line) == 0 {
+			break
+		}
+
+		knownHosts.lines = append(knownHosts.lines, parseKnownHost(strings.TrimRight(line, "\r\n")))
+	}
+
+	return nil
+}
+
+func parseKnownHost(line string) *KnownHost {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return &KnownHost{raw: line}
+	}
+
+	knownHost := &KnownHost{}
+	if strings.HasPrefix(fields[0], "@") {
+		knownHost.Marker = fields[0]
+		fields = fields[1:]
+	}
+	if len(fields) < 3 {
+		return &KnownHost{raw: line} // keep malformed lines untouched
+	}
+
+	knownHost.Hosts = strings.Split(fields[0], ",")
+	knownHost.KeyType = fields[1]
+	knownHost.Key = fields[2]
+	knownHost.Comment = strings.Join(fields[3:], " ")
+
+	return knownHost
+}
+
+// KnownHostAddress holds host names and the IP address recorded together for them in known_hosts, e.g. by
+// 'CheckHostIP yes' as in web01,10.0.0.5 ssh-ed25519 AAAA...
+type KnownHostAddress struct {
+	Aliases []string
+	Address string
+	Port    string // empty for the default port
+}
+
+// Addresses returns the host names and IP addresses of all unhashed lines recording both in the order of the
+// file, once per combination at its last line
+func (knownHosts *KnownHosts) Addresses() []*KnownHostAddress {
+	addresses := make([]*KnownHostAddress, 0, 10)
+	seen := make(map[string]bool)
+	for _, line := range knownHosts.lines {
+		if line.KeyType == "" || line.Marker != "" || line.Hashed() {
+			continue
+		}
+
+		address := &KnownHostAddress{Aliases: make([]string, 0, len(line.Hosts))}
+		for i, pattern := range line.Hosts {
+			host, port := splitKnownHostsName(pattern)
+			if i == 0 {
+				address.Port = port
+			} else if port != address.Port {
+				continue // patterns of other ports belong to other servers
+			}
+
+			if net.ParseIP(host) != nil {
+				if address.Address == "" {
+					address.Address = host
+				}
+			} else if helpers.IsValidHostname(host) && !helpers.SliceContains(address.Aliases, host) {
+				address.Aliases = append(address.Aliases, host)
+			}
+		}
+		if address.Address == "" || len(address.Aliases) == 0 {
+			continue
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	// keep the last line of each combination, which is the latest recorded by ssh
+	unique := make([]*KnownHostAddress, 0, len(addresses))
+	for i := len(addresses) - 1; i >= 0; i-- {
+		key := strings.Join(addresses[i].Aliases, ",") + " " + KnownHostsName(addresses[i].Address, addresses[i].Port)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, addresses[i])
+		}
+	}
+	for i, j := 0, len(unique)-1; i < j; i, j = i+1, j-1 {
+		unique[i], unique[j] = unique[j], unique[i]
+	}
+
+	return unique
+}
+
+// splitKnownHostsName splits [host]:port into host and port; port is empty for plain host names
+func splitKnownHostsName(name string) (string, string) {
+	if strings.HasPrefix(name, "[") {
+		if host, port, err := net.SplitHostPort(name); err == nil {
+			return host, port
+		}
+	}
+
+	return name, ""
+}
+
+// RenameHost replaces host by newHost in all matching lines, re-hashing hashed hosts, and returns the number of changed lines
+func (knownHosts *KnownHosts) RenameHost(host string, newHost string) (int, error) {
+	renamed := 0
+	for _, line := range knownHosts.lines {
+		if line.KeyType == "" || !line.Matches(host) {
+			continue
+		}
+
+		if line.Hashed() {
+			hashed, err := HashHost(newHost)
+			if err != nil {
+				return renamed, err
+			}
+			line.Hosts = []string{hashed}
+		} else {
+			for i, pattern := range line.Hosts {
+				if pattern == host {
+					line.Hosts[i] = newHost
+				}
+			}
+			line.Hosts = helpers.UniqueStrings(line.Hosts)
+		}
+
+		renamed++
+	}
+
+	return renamed, nil
+}
+
+// RemoveHost removes the keys of host, e.g. web01 or [web01]:2222, and returns the number of changed lines. Hashed
+// lines of host are removed, host patterns are removed from plain lines, which are removed once no host is left.
+// Lines with a marker like @revoked are kept.
+func (knownHosts *KnownHosts) RemoveHost(host string) int {
+	changed := 0
+	lines := make([]*KnownHost, 0, len(knownHosts.lines))
+	for _, line := range knownHosts.lines {
+		if line.KeyType == "" || line.Marker != "" || !line.Matches(host) {
+			lines = append(lines, line)
+			continue
+		}
+
+		changed++
+		if line.Hashed() {
+			continue
+		}
+
+		patterns := make([]string, 0, len(line.Hosts))
+		for _, pattern := range line.Hosts {
+			if pattern != host {
+				patterns = append(patterns, pattern)
+			}
+		}
+		if len(patterns) > 0 {
+			line.Hosts = patterns
+			lines = append(lines, line)
+		}
+	}
+	knownHosts.lines = lines
+
+	return changed
+}
+
+// ParseHostKey parses a public key as in a .pub file, e.g. ssh-ed25519 AAAA... comment, or a line of ssh-keyscan
+// with the host in front. The key has to be base64 encoded and of the given type.
+func ParseHostKey(text string) (*KnownHost, error) {
+	fields := strings.Fields(text)
+	if len(fields) >= 3 && !isKeyType(fields[0]) && isKeyType(fields[1]) {
+		fields = fields[1:] // ssh-keyscan output
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("expecting key type and key, e.g. ssh-ed25519 AAAA...")
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s key: %v", fields[0], err)
+	}
+	// the key starts with its type prefixed by its length
+	if len(blob) < 4 || int64(binary.BigEndian.Uint32(blob)) > int64(len(blob)-4) {
+		return nil, fmt.Errorf("invalid %s key", fields[0])
+	}
+	if keyType := string(blob[4 : 4+binary.BigEndian.Uint32(blob)]); keyType != fields[0] {
+		return nil, fmt.Errorf("invalid %s key: key is of type '%s'", fields[0], keyType)
+	}
+
+	return &KnownHost{KeyType: fields[0], Key: fields[1], Comment: strings.Join(fields[2:], " ")}, nil
+}
+
+// ParseHostKeys parses all keys of content, one per line, ignoring empty lines and comments
+func ParseHostKeys(content string) ([]*KnownHost, error) {
+	keys := make([]*KnownHost, 0, 3)
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, err := ParseHostKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys found")
+	}
+
+	return keys, nil
+}
+
+func isKeyType(field string) bool {
+	return strings.HasPrefix(field, "ssh-") || strings.HasPrefix(field, "ecdsa-") || strings.HasPrefix(field, "sk-")
+}
+
+// PinKeys replaces all keys recorded for hosts by keys. Hashed writes a line per host with its name hashed,
+// otherwise a single line per key lists all hosts.
+func (knownHosts *KnownHosts) PinKeys(hosts []string, keys []*KnownHost, hashed bool) error {
+	for _, host := range hosts {
+		knownHosts.RemoveHost(host)
+	}
+
+	patterns := [][]string{hosts}
+	if hashed {
+		patterns = make([][]string, len(hosts))
+		for i, host := range hosts {
+			hashedHost, err := HashHost(host)
+			if err != nil {
+				return err
+			}
+			patterns[i] = []string{hashedHost}
+		}
+	}
+
+	for _, key := range keys {
+		for _, hostPatterns := range patterns {
+			knownHosts.lines = append(knownHosts.lines, &KnownHost{Hosts: hostPatterns, KeyType: key.KeyType, Key: key.Key, Comment: key.Comment})
+		}
+	}
+
+	return nil
+}
+
+func (knownHosts *KnownHosts) Write() error {
+	file, err := os.OpenFile(knownHosts.filepath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to open '%s': %v", knownHosts.filepath, err)
+	}
+	defer file.Close()
+
+	err = file.Truncate(0)
+	if err != nil {
+		return fmt.Errorf("Failed writing file '%s': %v", file.Name(), err)
+	}
+
+	_, err = file.WriteAt(knownHosts.Bytes(), 0)
+	if err != nil {
+		return fmt.Errorf("Failed writing file '%s': %v", file.Name(), err)
+	}
+
+	return nil
+}
+
+func GetKnownHosts(filepath string) (*KnownHosts, error) {
+	knownHosts := &KnownHosts{
+		filepath: filepath,
+		lines:    make([]*KnownHost, 0, 10),
+	}
+
+	err := knownHosts.Read()
+
+	return knownHosts, err
+}
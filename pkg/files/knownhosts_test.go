@@ -0,0 +1,238 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+// hashedWeb01 is web01 hashed by ssh-keygen -H with the salt 00 01 02 ... 13
+const hashedWeb01 = "|1|AAECAwQFBgcICQoLDA0ODxAREhM=|4+fFBRULyuyLCYLaQAssQWPXz8o="
+
+const testKey = "AAAAC3NzaC1lZDI1NTE5AAAAINjRTs5RFw7UugeaNwOvTqk9KeHWZc5x1u7UN8TqfJ3/"
+
+func testKnownHosts(content string) *KnownHosts {
+	knownHosts := &KnownHosts{}
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		knownHosts.lines = append(knownHosts.lines, parseKnownHost(line))
+	}
+
+	return knownHosts
+}
+
+func TestKnownHostMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		host  string
+		match bool
+	}{
+		{"plain", "web01 ssh-ed25519 " + testKey, "web01", true},
+		{"plain list", "web01,10.0.0.1 ssh-ed25519 " + testKey, "10.0.0.1", true},
+		{"plain other", "web01 ssh-ed25519 " + testKey, "web02", false},
+		{"plain port", "[web01]:2222 ssh-ed25519 " + testKey, "web01", false},
+		{"hashed", hashedWeb01 + " ssh-ed25519 " + testKey, "web01", true},
+		{"hashed other", hashedWeb01 + " ssh-ed25519 " + testKey, "web02", false},
+		{"hashed port", "|1|AAECAwQFBgcICQoLDA0ODxAREhM=|mvP8Ob4CUGVZYpwYD6AcFH8Gwfk= ssh-ed25519 " + testKey, "[web01]:2222", true},
+		{"hashed address", "|1|AAECAwQFBgcICQoLDA0ODxAREhM=|xlhPPKn5YMSu7VD+2WlQW37pfkw= ssh-ed25519 " + testKey, "10.0.0.1", true},
+		{"hashed bad salt", "|1|not-base64|4+fFBRULyuyLCYLaQAssQWPXz8o= ssh-ed25519 " + testKey, "web01", false},
+		{"hashed missing hash", "|1|AAECAwQFBgcICQoLDA0ODxAREhM= ssh-ed25519 " + testKey, "web01", false},
+		{"marker", "@revoked web01 ssh-ed25519 " + testKey, "web01", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseKnownHost(test.line).Matches(test.host); got != test.match {
+				t.Errorf("Matches(%q) = %v, want %v", test.host, got, test.match)
+			}
+		})
+	}
+}
+
+func TestHashHost(t *testing.T) {
+	for _, host := range []string{"web01", "[web01]:2222", "10.0.0.1"} {
+		t.Run(host, func(t *testing.T) {
+			hashed, err := HashHost(host)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			line := parseKnownHost(hashed + " ssh-ed25519 " + testKey)
+			if !line.Hashed() {
+				t.Errorf("%s is not hashed", hashed)
+			}
+			if !line.Matches(host) {
+				t.Errorf("%s does not match %s", hashed, host)
+			}
+			if line.Matches(host + "x") {
+				t.Errorf("%s matches %sx", hashed, host)
+			}
+
+			again, _ := HashHost(host)
+			if again == hashed {
+				t.Errorf("hashing twice gave the same salt")
+			}
+		})
+	}
+}
+
+func TestKnownHostsRenameHost(t *testing.T) {
+	content := "web01,10.0.0.1 ssh-ed25519 " + testKey + "\n" +
+		hashedWeb01 + " ssh-ed25519 " + testKey + "\n" +
+		"# web01 comment\n" +
+		"web02 ssh-ed25519 " + testKey + "\n"
+
+	knownHosts := testKnownHosts(content)
+	renamed, err := knownHosts.RenameHost("web01", "web03")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renamed != 2 {
+		t.Errorf("got %d renamed lines, want 2", renamed)
+	}
+
+	lines := knownHosts.Lines()
+	if got := lines[0].String(); got != "web03,10.0.0.1 ssh-ed25519 "+testKey {
+		t.Errorf("got %q", got)
+	}
+	if !lines[1].Hashed() || !lines[1].Matches("web03") || lines[1].Matches("web01") {
+		t.Errorf("hashed line %q does not match web03 only", lines[1].String())
+	}
+	if got := lines[2].String(); got != "# web01 comment" {
+		t.Errorf("got %q, want comment kept", got)
+	}
+	if got := lines[3].String(); got != "web02 ssh-ed25519 "+testKey {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestKnownHostsRemoveHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		host    string
+		want    string
+		changed int
+	}{
+		{
+			name:    "plain line",
+			content: "web01 ssh-ed25519 " + testKey + "\ndb01 ssh-ed25519 " + testKey,
+			host:    "web01",
+			want:    "db01 ssh-ed25519 " + testKey,
+			changed: 1,
+		},
+		{
+			name:    "pattern of shared line",
+			content: "web01,10.0.0.1 ssh-ed25519 " + testKey,
+			host:    "web01",
+			want:    "10.0.0.1 ssh-ed25519 " + testKey,
+			changed: 1,
+		},
+		{
+			name:    "hashed line",
+			content: "# comment\n" + hashedWeb01 + " ssh-ed25519 " + testKey,
+			host:    "web01",
+			want:    "# comment",
+			changed: 1,
+		},
+		{
+			name:    "revoked kept",
+			content: "@revoked web01 ssh-ed25519 " + testKey,
+			host:    "web01",
+			want:    "@revoked web01 ssh-ed25519 " + testKey,
+			changed: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			knownHosts := testKnownHosts(test.content)
+
+			if changed := knownHosts.RemoveHost(test.host); changed != test.changed {
+				t.Errorf("changed %d lines, want %d", changed, test.changed)
+			}
+			if got := knownHosts.String(); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseHostKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		comment string
+		err     bool
+	}{
+		{name: "pub file", text: "ssh-ed25519 " + testKey + " root@web01", comment: "root@web01"},
+		{name: "keyscan", text: "web01 ssh-ed25519 " + testKey},
+		{name: "type mismatch", text: "ssh-rsa " + testKey, err: true},
+		{name: "not base64", text: "ssh-ed25519 AAAA!", err: true},
+		{name: "truncated", text: "ssh-ed25519 AAAA", err: true},
+		{name: "missing key", text: "ssh-ed25519", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := ParseHostKey(test.text)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %v, want error", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key.KeyType != "ssh-ed25519" || key.Key != testKey || key.Comment != test.comment {
+				t.Errorf("got %s", key)
+			}
+		})
+	}
+}
+
+func TestKnownHostsAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "host and address",
+			content: "web01,10.0.0.1 ssh-ed25519 " + testKey,
+			want:    []string{"web01 10.0.0.1"},
+		},
+		{
+			name:    "port",
+			content: "[web01]:2222,[10.0.0.1]:2222 ssh-ed25519 " + testKey,
+			want:    []string{"web01 [10.0.0.1]:2222"},
+		},
+		{
+			name:    "skips lines without address, hashed and marked lines",
+			content: "web01 ssh-ed25519 " + testKey + "\n" + hashedWeb01 + " ssh-ed25519 " + testKey + "\n@revoked web02,10.0.0.2 ssh-ed25519 " + testKey,
+			want:    []string{},
+		},
+		{
+			name:    "new address recorded later",
+			content: "web01,10.0.0.1 ssh-ed25519 " + testKey + "\nweb01,10.0.0.2 ssh-ed25519 " + testKey,
+			want:    []string{"web01 10.0.0.1", "web01 10.0.0.2"},
+		},
+		{
+			name:    "combination at its last line",
+			content: "web01,10.0.0.1 ssh-ed25519 " + testKey + "\nweb01,10.0.0.2 ssh-ed25519 " + testKey + "\nweb01,10.0.0.1 ssh-rsa " + testKey,
+			want:    []string{"web01 10.0.0.2", "web01 10.0.0.1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, address := range testKnownHosts(test.content).Addresses() {
+				got = append(got, strings.Join(address.Aliases, ",")+" "+KnownHostsName(address.Address, address.Port))
+			}
+			if strings.Join(got, "; ") != strings.Join(test.want, "; ") {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package files
+
+import "strings"
+
+// disabledMarker prefixes lines of entries that have been switched off
+const disabledMarker = "#[disabled]"
+
+func cutDisabledMarker(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, disabledMarker) {
+		return strings.TrimPrefix(trimmed, disabledMarker), true
+	}
+
+	return line, false
+}
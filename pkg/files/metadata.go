@@ -0,0 +1,193 @@
+package files
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+)
+
+// metadataPrefix starts the comment holding metadata of an entry, e.g. # hosts-cli: profile=staging
+const metadataPrefix = "hosts-cli:"
+
+// Metadata holds key-value pairs the CLI stores in comments next to the entries it manages
+type Metadata map[string]string
+
+// managedMetaKey marks entries created by the CLI, e.g. managed=true. Entries without it were written by hand or other tools.
+const managedMetaKey = "managed"
+
+// expiresMetaKey holds the time after which an entry is removed by 'hosts gc', e.g. expires=2023-04-01T12:00:00Z
+const expiresMetaKey = "expires"
+
+// tagsMetaKey holds the comma separated tags of an entry, e.g. tags=k8s,lab
+const tagsMetaKey = "tags"
+
+// ownersMetaKey holds the comma separated owners sharing an entry, e.g. owners=stack-a,stack-b. Owners added with
+// a TTL carry their expiry, e.g. stack-b@2023-04-01T12:00:00Z, which 'hosts gc' releases them after.
+const ownersMetaKey = "owners"
+
+// ownerExpiresSeparator separates an owner from its expiry in the owners list
+const ownerExpiresSeparator = "@"
+
+func (meta Metadata) String() string {
+	if len(meta) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + meta[key]
+	}
+
+	return metadataPrefix + " " + strings.Join(pairs, " ")
+}
+
+func (meta Metadata) clone() Metadata {
+	if meta == nil {
+		return nil
+	}
+
+	clone := make(Metadata, len(meta))
+	for key, value := range meta {
+		clone[key] = value
+	}
+
+	return clone
+}
+
+// cutMetadata splits a comment into the user's comment and the metadata stored by the CLI
+func cutMetadata(comment string) (string, Metadata) {
+	before, after, found := strings.Cut(comment, metadataPrefix)
+	if !found {
+		return strings.TrimSpace(comment), nil
+	}
+
+	meta := Metadata{}
+	for _, pair := range strings.Fields(after) {
+		key, value, _ := strings.Cut(pair, "=")
+		meta[key] = value
+	}
+
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(before), "#")), meta
+}
+
+func getMeta(meta Metadata, key string) string {
+	if meta == nil {
+		return ""
+	}
+
+	return meta[key]
+}
+
+// setMeta sets key to value, an empty value removes the key
+func setMeta(meta Metadata, key string, value string) Metadata {
+	if value == "" {
+		delete(meta, key)
+
+		return meta
+	}
+
+	if meta == nil {
+		meta = Metadata{}
+	}
+	meta[key] = value
+
+	return meta
+}
+
+// getList returns the comma separated values of key
+func getList(meta Metadata, key string) []string {
+	value := getMeta(meta, key)
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+func addToList(meta Metadata, key string, values []string) Metadata {
+	return setMeta(meta, key, strings.Join(helpers.UniqueStrings(append(getList(meta, key), values...)), ","))
+}
+
+// getOwners returns the owners of the owners list without their expiry
+func getOwners(meta Metadata) []string {
+	records := getList(meta, ownersMetaKey)
+	owners := make([]string, len(records))
+	for i, record := range records {
+		owners[i], _, _ = strings.Cut(record, ownerExpiresSeparator)
+	}
+
+	return owners
+}
+
+// addOwner adds owner to the owners list or replaces its record, storing expires unless it is zero
+func addOwner(meta Metadata, owner string, expires time.Time) Metadata {
+	record := owner
+	if !expires.IsZero() {
+		record = owner + ownerExpiresSeparator + expires.UTC().Truncate(time.Second).Format(time.RFC3339)
+	}
+
+	return addToList(removeOwner(meta, owner), ownersMetaKey, []string{record})
+}
+
+func removeOwner(meta Metadata, owner string) Metadata {
+	kept := make([]string, 0, 5)
+	for _, record := range getList(meta, ownersMetaKey) {
+		if name, _, _ := strings.Cut(record, ownerExpiresSeparator); name != owner {
+			kept = append(kept, record)
+		}
+	}
+
+	return setMeta(meta, ownersMetaKey, strings.Join(kept, ","))
+}
+
+// releaseExpiredOwners removes all owners whose expiry has passed and returns them
+func releaseExpiredOwners(meta Metadata, now time.Time) (Metadata, []string) {
+	released := make([]string, 0)
+	for _, record := range getList(meta, ownersMetaKey) {
+		owner, value, found := strings.Cut(record, ownerExpiresSeparator)
+		if !found {
+			continue
+		}
+		if expires, err := time.Parse(time.RFC3339, value); err == nil && !now.Before(expires) {
+			meta = removeOwner(meta, owner)
+			released = append(released, owner)
+		}
+	}
+
+	return meta, released
+}
+
+func getExpires(meta Metadata) (time.Time, bool) {
+	expires, err := time.Parse(time.RFC3339, getMeta(meta, expiresMetaKey))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expires, true
+}
+
+func setExpires(meta Metadata, expires time.Time) Metadata {
+	return setMeta(meta, expiresMetaKey, expires.UTC().Truncate(time.Second).Format(time.RFC3339))
+}
+
+func isExpired(meta Metadata, now time.Time) bool {
+	expires, ok := getExpires(meta)
+
+	return ok && !now.Before(expires)
+}
+
+func setManaged(meta Metadata, managed bool) Metadata {
+	if !managed {
+		return setMeta(meta, managedMetaKey, "")
+	}
+
+	return setMeta(meta, managedMetaKey, "true")
+}
@@ -0,0 +1,206 @@
+package files
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCutMetadata(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    string
+		meta    string
+	}{
+		{comment: "", want: "", meta: ""},
+		{comment: " primary ", want: "primary", meta: ""},
+		{comment: " hosts-cli: profile=lab", want: "", meta: "hosts-cli: profile=lab"},
+		{comment: " primary # hosts-cli: profile=lab", want: "primary", meta: "hosts-cli: profile=lab"},
+		{comment: " hosts-cli: profile=lab managed=true", want: "", meta: "hosts-cli: managed=true profile=lab"},
+		{comment: " hosts-cli:", want: "", meta: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.comment, func(t *testing.T) {
+			comment, meta := cutMetadata(test.comment)
+			if comment != test.want {
+				t.Errorf("got comment %q, want %q", comment, test.want)
+			}
+			if got := meta.String(); got != test.meta {
+				t.Errorf("got metadata %q, want %q", got, test.meta)
+			}
+		})
+	}
+}
+
+func TestSetMeta(t *testing.T) {
+	var meta Metadata
+	if got := getMeta(meta, "profile"); got != "" {
+		t.Errorf("got %q from nil metadata", got)
+	}
+
+	meta = setMeta(meta, "profile", "lab")
+	if got := getMeta(meta, "profile"); got != "lab" {
+		t.Errorf("got %q, want lab", got)
+	}
+
+	clone := meta.clone()
+	clone = setMeta(clone, "profile", "")
+	if got := getMeta(meta, "profile"); got != "lab" {
+		t.Errorf("changing a clone changed the original to %q", got)
+	}
+	if got := clone.String(); got != "" {
+		t.Errorf("got %q, want empty metadata", got)
+	}
+}
+
+func TestAddTags(t *testing.T) {
+	hosts := testHosts(t, "10.0.0.1 web01 # hosts-cli: tags=lab\n")
+	entry := hosts.entries[0]
+
+	entry.AddTags("k8s", "lab")
+	if got := entry.String(); got != "10.0.0.1 web01 # hosts-cli: tags=lab,k8s" {
+		t.Errorf("got %q", got)
+	}
+	if !entry.HasTag("k8s") || entry.HasTag("prod") {
+		t.Errorf("got tags %q", entry.Tags())
+	}
+
+	sshConfig := testSSHConfig(t, "Host web01\n  User admin\n")
+	block := sshConfig.blocks[0]
+	if len(block.Tags()) != 0 {
+		t.Errorf("got tags %q for block without metadata", block.Tags())
+	}
+	block.AddTags("lab")
+	if !block.HasTag("lab") {
+		t.Errorf("got tags %q, want lab", block.Tags())
+	}
+}
+
+func TestExpires(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		meta    string
+		expired bool
+	}{
+		{name: "no expiry", meta: "", expired: false},
+		{name: "pending", meta: " hosts-cli: expires=2023-04-01T13:00:00Z", expired: false},
+		{name: "expired", meta: " hosts-cli: expires=2023-04-01T11:00:00Z", expired: true},
+		{name: "expires now", meta: " hosts-cli: expires=2023-04-01T12:00:00Z", expired: true},
+		{name: "invalid expiry", meta: " hosts-cli: expires=tomorrow", expired: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, meta := cutMetadata(test.meta)
+			host := &Host{address: "10.0.0.1", aliases: []string{"web01"}, meta: meta}
+			if got := host.Expired(now); got != test.expired {
+				t.Errorf("got expired %v, want %v", got, test.expired)
+			}
+		})
+	}
+
+	block := &HostBlock{Hosts: []string{"web01"}}
+	block.SetExpires(now.Add(90*time.Minute + 500*time.Millisecond).In(time.FixedZone("CET", 3600)))
+	if got := block.meta.String(); got != "hosts-cli: expires=2023-04-01T13:30:00Z" {
+		t.Errorf("got metadata %q", got)
+	}
+	if expires, ok := block.Expires(); !ok || !expires.Equal(now.Add(90*time.Minute)) {
+		t.Errorf("got expiry %v, %v", expires, ok)
+	}
+}
+
+func TestOwnerExpiry(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		owners    string
+		add       string
+		expires   time.Time
+		remaining []string
+		released  []string
+		meta      string
+	}{
+		{
+			name:      "owner without ttl is kept",
+			add:       "a",
+			remaining: []string{"a"},
+			released:  []string{},
+			meta:      "owners=a",
+		},
+		{
+			name:      "expired owner is released",
+			owners:    "a",
+			add:       "b",
+			expires:   now.Add(-time.Minute),
+			remaining: []string{"a"},
+			released:  []string{"b"},
+			meta:      "owners=a",
+		},
+		{
+			name:      "pending owner is kept",
+			owners:    "a",
+			add:       "b",
+			expires:   now.Add(time.Hour),
+			remaining: []string{"a", "b"},
+			released:  []string{},
+			meta:      "owners=a,b@2023-04-01T13:00:00Z",
+		},
+		{
+			name:      "adding again replaces expiry",
+			owners:    "a@2023-04-01T11:00:00Z",
+			add:       "a",
+			remaining: []string{"a"},
+			released:  []string{},
+			meta:      "owners=a",
+		},
+		{
+			name:      "last owner released",
+			owners:    "a@2023-04-01T11:00:00Z",
+			add:       "b",
+			expires:   now,
+			remaining: []string{},
+			released:  []string{"a", "b"},
+			meta:      "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			host := &Host{address: "10.0.0.1", aliases: []string{"web01"}}
+			if test.owners != "" {
+				host.meta = Metadata{ownersMetaKey: test.owners}
+			}
+
+			host.AddOwner(test.add, test.expires)
+			released := host.ReleaseExpiredOwners(now)
+
+			if strings.Join(released, ",") != strings.Join(test.released, ",") {
+				t.Errorf("released %v, want %v", released, test.released)
+			}
+			if strings.Join(host.Owners(), ",") != strings.Join(test.remaining, ",") {
+				t.Errorf("got owners %v, want %v", host.Owners(), test.remaining)
+			}
+			if got := strings.TrimPrefix(host.meta.String(), metadataPrefix+" "); got != test.meta {
+				t.Errorf("got metadata %q, want %q", got, test.meta)
+			}
+		})
+	}
+}
+
+func TestClearMeta(t *testing.T) {
+	hosts := testHosts(t, "10.0.0.1 web01 # primary # hosts-cli: managed=true tags=lab\n")
+	hosts.entries[0].ClearMeta()
+	if got := hosts.entries[0].String(); got != "10.0.0.1 web01 # primary" {
+		t.Errorf("got %q", got)
+	}
+
+	sshConfig := testSSHConfig(t, "Host web01\n  # hosts-cli: managed=true owners=a\n  HostName 10.0.0.1\n")
+	sshConfig.blocks[0].ClearMeta()
+	if got := sshConfig.blocks[0].String(); got != "Host web01\n  HostName 10.0.0.1\n" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package files
+
+import (
+	"os"
+	"syscall"
+)
+
+// copyOwner applies owner and group of info to path; failures are ignored as only root may chown
+func copyOwner(path string, info os.FileInfo) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		os.Chown(path, int(stat.Uid), int(stat.Gid))
+	}
+}
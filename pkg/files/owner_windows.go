@@ -0,0 +1,8 @@
+//go:build windows
+
+package files
+
+import "os"
+
+// copyOwner is a no-op on Windows where files inherit ownership from their directory
+func copyOwner(path string, info os.FileInfo) {}
@@ -0,0 +1,194 @@
+package files
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Action string
+
+const (
+	Create  Action = "create"
+	Update  Action = "update"
+	Destroy Action = "destroy"
+)
+
+// Change is a single action of a Plan on a hosts file entry or ssh config block
+type Change struct {
+	Action Action
+	Name   string // first alias or host of the entry
+	Before string // entry as currently written; empty for Create
+	After  string // entry as declared; empty for Destroy
+
+	oldHost, newHost   *Host
+	oldBlock, newBlock *HostBlock
+}
+
+// Plan holds the changes turning the current files into the state declared by a Spec
+type Plan struct {
+	Spec       string
+	Hosts      []*Change
+	Blocks     []*Change
+	Undeclared int // entries written by the spec that are no longer declared but kept without prune
+}
+
+// Counts returns the number of entries to create, update and destroy
+func (plan *Plan) Counts() (int, int, int) {
+	counts := map[Action]int{}
+	for _, change := range append(append([]*Change{}, plan.Hosts...), plan.Blocks...) {
+		counts[change.Action]++
+	}
+
+	return counts[Create], counts[Update], counts[Destroy]
+}
+
+func (plan *Plan) Empty() bool {
+	return len(plan.Hosts) == 0 && len(plan.Blocks) == 0
+}
+
+// Apply performs the changes on hosts and sshConfig; hosts may be nil if the plan has no hosts file changes
+func (plan *Plan) Apply(hosts *Hosts, sshConfig *SSHConfig) {
+	for _, change := range plan.Hosts {
+		switch change.Action {
+		case Create:
+			hosts.ReplaceEntries(nil, []*Host{change.newHost})
+		case Update:
+			hosts.ReplaceEntries([]*Host{change.oldHost}, []*Host{change.newHost})
+		case Destroy:
+			hosts.RemoveEntry(change.oldHost)
+		}
+	}
+
+	for _, change := range plan.Blocks {
+		switch change.Action {
+		case Create:
+			sshConfig.ReplaceBlocks(nil, []*HostBlock{change.newBlock})
+		case Update:
+			sshConfig.ReplaceBlocks([]*HostBlock{change.oldBlock}, []*HostBlock{change.newBlock})
+		case Destroy:
+			sshConfig.RemoveBlock(change.oldBlock)
+		}
+	}
+}
+
+// PlanSpec compares the entries written by spec with its declarations. Entries of the spec which are no longer
+// declared are destroyed with prune. Declared aliases mapped by other entries are conflicts which fail the plan
+// unless adopt is given, which destroys those entries. The hosts file is skipped if hosts is nil.
+func PlanSpec(spec *Spec, hosts *Hosts, sshConfig *SSHConfig, prune bool, adopt bool) (*Plan, error) {
+	plan := &Plan{Spec: spec.Name}
+	conflicts := make([]string, 0)
+
+	if hosts != nil {
+		current := make(map[string]*Host)
+		order := make([]*Host, 0, len(spec.Hosts))
+		for _, entry := range hosts.entries {
+			if len(entry.aliases) > 0 && entry.GetMeta(specMetaKey) == spec.Name {
+				order = append(order, entry)
+				if _, ok := current[entry.aliases[0]]; !ok {
+					current[entry.aliases[0]] = entry
+				}
+			}
+		}
+
+		declared := make(map[*Host]bool)
+		adopted := make(map[*Host]bool)
+		for _, entry := range spec.Entries() {
+			name := entry.aliases[0]
+
+			for _, alias := range entry.aliases {
+				for _, other := range hosts.FindEntries(alias) {
+					if other.GetMeta(specMetaKey) == spec.Name || adopted[other] {
+						continue
+					}
+					if !adopt || other.Required() {
+						conflicts = append(conflicts, fmt.Sprintf("'%s' is mapped by '%s' in %s", alias, other, hosts.filepath))
+						continue
+					}
+					adopted[other] = true
+					plan.Hosts = append(plan.Hosts, &Change{Action: Destroy, Name: other.aliases[0], Before: other.String(), oldHost: other})
+				}
+			}
+
+			existing, ok := current[name]
+			if !ok {
+				plan.Hosts = append(plan.Hosts, &Change{Action: Create, Name: name, After: entry.String(), newHost: entry})
+				continue
+			}
+
+			declared[existing] = true
+			if existing.String() != entry.String() {
+				plan.Hosts = append(plan.Hosts, &Change{Action: Update, Name: name, Before: existing.String(), After: entry.String(), oldHost: existing, newHost: entry})
+			}
+		}
+
+		for _, entry := range order {
+			if declared[entry] {
+				continue
+			}
+			if !prune {
+				plan.Undeclared++
+				continue
+			}
+			plan.Hosts = append(plan.Hosts, &Change{Action: Destroy, Name: entry.aliases[0], Before: entry.String(), oldHost: entry})
+		}
+	}
+
+	current := make(map[string]*HostBlock)
+	order := make([]*HostBlock, 0, len(spec.SSH))
+	for _, block := range sshConfig.blocks {
+		if len(block.Hosts) > 0 && block.GetMeta(specMetaKey) == spec.Name {
+			order = append(order, block)
+			if _, ok := current[block.Hosts[0]]; !ok {
+				current[block.Hosts[0]] = block
+			}
+		}
+	}
+
+	declared := make(map[*HostBlock]bool)
+	adopted := make(map[*HostBlock]bool)
+	for _, block := range spec.Blocks() {
+		name := block.Hosts[0]
+
+		for _, alias := range block.Hosts {
+			for _, other := range sshConfig.FindBlocks(alias) {
+				if other.GetMeta(specMetaKey) == spec.Name || adopted[other] {
+					continue
+				}
+				if !adopt {
+					conflicts = append(conflicts, fmt.Sprintf("'%s' is configured by 'Host %s' in %s", alias, strings.Join(other.Hosts, " "), sshConfig.filepath))
+					continue
+				}
+				adopted[other] = true
+				plan.Blocks = append(plan.Blocks, &Change{Action: Destroy, Name: other.Hosts[0], Before: other.String(), oldBlock: other})
+			}
+		}
+
+		existing, ok := current[name]
+		if !ok {
+			plan.Blocks = append(plan.Blocks, &Change{Action: Create, Name: name, After: block.String(), newBlock: block})
+			continue
+		}
+
+		declared[existing] = true
+		if existing.String() != block.String() {
+			plan.Blocks = append(plan.Blocks, &Change{Action: Update, Name: name, Before: existing.String(), After: block.String(), oldBlock: existing, newBlock: block})
+		}
+	}
+
+	for _, block := range order {
+		if declared[block] {
+			continue
+		}
+		if !prune {
+			plan.Undeclared++
+			continue
+		}
+		plan.Blocks = append(plan.Blocks, &Change{Action: Destroy, Name: block.Hosts[0], Before: block.String(), oldBlock: block})
+	}
+
+	if len(conflicts) > 0 {
+		return plan, fmt.Errorf("Failed planning spec '%s': declared aliases are already in use by entries not written by it:\n  %s", spec.Name, strings.Join(conflicts, "\n  "))
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,109 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const profileExtension = ".profile"
+
+var profileNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// Profile is a named set of hosts file entries and ssh config blocks that can be switched on and off as a unit
+type Profile struct {
+	Name     string
+	filepath string
+	Snippet  *Snippet
+}
+
+func (profile *Profile) String() string {
+	return profile.Snippet.String()
+}
+
+func (profile *Profile) Filepath() string {
+	return profile.filepath
+}
+
+func (profile *Profile) Read() error {
+	content, err := os.ReadFile(profile.filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Profile '%s' does not exist", profile.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to open '%s': %v", profile.filepath, err)
+	}
+
+	snippet, err := ParseSnippet(string(content))
+	if err != nil {
+		return fmt.Errorf("Failed parsing profile '%s':\n%v", profile.Name, err)
+	}
+	profile.Snippet = snippet
+
+	return nil
+}
+
+func (profile *Profile) Write() error {
+	if err := os.MkdirAll(filepath.Dir(profile.filepath), 0700); err != nil {
+		return fmt.Errorf("Failed to create directory for '%s': %v", profile.filepath, err)
+	}
+
+	if err := os.WriteFile(profile.filepath, []byte(profile.String()), 0600); err != nil {
+		return fmt.Errorf("Failed writing file '%s': %v", profile.filepath, err)
+	}
+
+	return nil
+}
+
+func IsValidProfileName(name string) bool {
+	return profileNameRegexp.MatchString(name)
+}
+
+func ProfilePath(dir string, name string) string {
+	return filepath.Join(dir, name+profileExtension)
+}
+
+func GetProfile(dir string, name string) (*Profile, error) {
+	profile := &Profile{
+		Name:     name,
+		filepath: ProfilePath(dir, name),
+	}
+
+	err := profile.Read()
+
+	return profile, err
+}
+
+// NewProfile returns a profile of snippet stored in dir, which is not written until Write is called
+func NewProfile(dir string, name string, snippet *Snippet) *Profile {
+	return &Profile{
+		Name:     name,
+		filepath: ProfilePath(dir, name),
+		Snippet:  snippet,
+	}
+}
+
+// ListProfiles returns the names of all profiles stored in dir
+func ListProfiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read '%s': %v", dir, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), profileExtension) {
+			names = append(names, strings.TrimSuffix(entry.Name(), profileExtension))
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
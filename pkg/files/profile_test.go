@@ -0,0 +1,89 @@
+package files
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIsValidProfileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"staging", true},
+		{"lab-01.v2", true},
+		{"Lab_01", true},
+		{"", false},
+		{".hidden", false},
+		{"-lab", false},
+		{"lab/01", false},
+		{"lab 01", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsValidProfileName(test.name); got != test.valid {
+				t.Errorf("IsValidProfileName(%q) = %v, want %v", test.name, got, test.valid)
+			}
+		})
+	}
+}
+
+func TestProfileWriteRead(t *testing.T) {
+	dir := t.TempDir() + "/profiles"
+
+	snippet, err := ParseSnippet("# --- hosts file ---\n10.0.0.1 web01 # hosts-cli: profile=lab\n# --- ssh config ---\nHost web01\n  # hosts-cli: profile=lab\n  HostName 10.0.0.1\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := NewProfile(dir, "lab", snippet).Write(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(ProfilePath(dir, "lab"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got permissions %v, want 0600", info.Mode().Perm())
+	}
+
+	profile, err := GetProfile(dir, "lab")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := profile.String(), snippet.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := GetProfile(dir, "prod"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("got %v, want error for missing profile", err)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	names, err := ListProfiles(dir + "/missing")
+	if err != nil || len(names) != 0 {
+		t.Errorf("got %v, %v for missing directory, want no profiles", names, err)
+	}
+
+	for _, name := range []string{"staging.profile", "lab.profile", "notes.txt"} {
+		if err := os.WriteFile(dir+"/"+name, []byte{}, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(dir+"/old.profile", 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err = ListProfiles(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(names, " "); got != "lab staging" {
+		t.Errorf("got %q, want %q", got, "lab staging")
+	}
+}
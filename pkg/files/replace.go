@@ -0,0 +1,59 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// ReplaceFile atomically replaces the file at path by writing to a temporary file next to it and renaming it.
+// Permissions and, if possible, ownership of the original file are kept.
+func ReplaceFile(path string, content []byte) error {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("Failed to resolve '%s': %v", path, err)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("Failed to stat '%s': %v", target, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary file for '%s': %v", target, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after successful rename
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Failed writing file '%s': %v", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Failed writing file '%s': %v", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Failed writing file '%s': %v", tmp.Name(), err)
+	}
+
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("Failed to set permissions of '%s': %v", tmp.Name(), err)
+	}
+	copyOwner(tmp.Name(), info)
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		if !errors.Is(err, syscall.EBUSY) && !errors.Is(err, syscall.EXDEV) {
+			return fmt.Errorf("Failed to replace '%s': %v", target, err)
+		}
+
+		// bind mounted files like /etc/hosts in containers can not be replaced, so overwrite them in place
+		if err := os.WriteFile(target, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("Failed to replace '%s': %v", target, err)
+		}
+	}
+
+	return nil
+}
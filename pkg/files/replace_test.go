@@ -0,0 +1,73 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		perm    os.FileMode
+		symlink bool
+	}{
+		{name: "keeps permissions", perm: 0644},
+		{name: "keeps private permissions", perm: 0600},
+		{name: "replaces symlink target", perm: 0644, symlink: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "hosts")
+			if err := os.WriteFile(target, []byte("old\n"), test.perm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(target, test.perm); err != nil {
+				t.Fatal(err)
+			}
+
+			path := target
+			if test.symlink {
+				path = filepath.Join(dir, "link")
+				if err := os.Symlink(target, path); err != nil {
+					t.Skipf("symlinks not supported: %v", err)
+				}
+			}
+
+			if err := ReplaceFile(path, []byte("new\n")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != "new\n" {
+				t.Errorf("got content %q, want %q", content, "new\n")
+			}
+			info, err := os.Lstat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.symlink && info.Mode()&os.ModeSymlink == 0 {
+				t.Errorf("symlink was replaced by a file")
+			}
+			if info, _ := os.Stat(target); info.Mode().Perm() != test.perm {
+				t.Errorf("got permissions %v, want %v", info.Mode().Perm(), test.perm)
+			}
+
+			entries, _ := os.ReadDir(dir)
+			if want := map[bool]int{false: 1, true: 2}[test.symlink]; len(entries) != want {
+				t.Errorf("got %d files, want %d; temporary file left behind", len(entries), want)
+			}
+		})
+	}
+}
+
+func TestReplaceFileMissing(t *testing.T) {
+	if err := ReplaceFile(filepath.Join(t.TempDir(), "missing"), []byte("new\n")); err == nil {
+		t.Error("expected error replacing a missing file")
+	}
+}
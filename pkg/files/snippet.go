@@ -0,0 +1,169 @@
+package files
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+)
+
+const (
+	hostsSectionHeader     = "# --- hosts file ---"
+	sshConfigSectionHeader = "# --- ssh config ---"
+)
+
+// Snippet holds hosts file entries and ssh config blocks detached from their files, e.g. all entries of a single host.
+// Hosts or Blocks are nil if the snippet has no such section.
+type Snippet struct {
+	Hosts  []*Host
+	Blocks []*HostBlock
+}
+
+func (snippet *Snippet) String() string {
+	var output strings.Builder
+
+	if snippet.Hosts != nil {
+		output.WriteString(hostsSectionHeader + "\n")
+		for _, host := range snippet.Hosts {
+			output.WriteString(host.String() + "\n")
+		}
+	}
+
+	if snippet.Blocks != nil {
+		if snippet.Hosts != nil {
+			output.WriteString("\n")
+		}
+		output.WriteString(sshConfigSectionHeader + "\n")
+		for i, block := range snippet.Blocks {
+			if i > 0 {
+				output.WriteString("\n")
+			}
+			output.WriteString(block.String())
+		}
+	}
+
+	return output.String()
+}
+
+// Aliases returns all host names of the snippet, skipping patterns like * or !bastion
+func (snippet *Snippet) Aliases() []string {
+	aliases := make([]string, 0, 10)
+	for _, host := range snippet.Hosts {
+		aliases = append(aliases, host.aliases...)
+	}
+	for _, block := range snippet.Blocks {
+		if strings.ToUpper(block.Kind) != "HOST" {
+			continue
+		}
+		for _, pattern := range block.Hosts {
+			if !strings.ContainsAny(pattern, "*?!") {
+				aliases = append(aliases, pattern)
+			}
+		}
+	}
+
+	return helpers.UniqueStrings(aliases)
+}
+
+type snippetSection struct {
+	offset  int
+	content strings.Builder
+	found   bool
+}
+
+// ParseSnippet parses content as written by Snippet.String and returns ParseErrors for all invalid lines
+func ParseSnippet(content string) (*Snippet, error) {
+	errs := make(ParseErrors, 0)
+
+	var hostsSection, sshConfigSection snippetSection
+	var current *snippetSection
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+
+		switch strings.TrimSpace(line) {
+		case hostsSectionHeader:
+			current = &hostsSection
+			current.found = true
+			current.offset = lineNumber
+			continue
+		case sshConfigSectionHeader:
+			current = &sshConfigSection
+			current.found = true
+			current.offset = lineNumber
+			continue
+		}
+
+		if current == nil {
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				errs = append(errs, &ParseError{Line: lineNumber, Message: "content outside of hosts file or ssh config section"})
+			}
+			continue
+		}
+
+		current.content.WriteString(line + "\n")
+	}
+
+	snippet := &Snippet{}
+	if hostsSection.found {
+		hosts, err := parseHosts(strings.NewReader(hostsSection.content.String()))
+		errs = append(errs, offsetErrors(err, hostsSection.offset)...)
+		snippet.Hosts = hosts
+	}
+	if sshConfigSection.found {
+		blocks, err := parseSSHConfig(strings.NewReader(sshConfigSection.content.String()))
+		errs = append(errs, offsetErrors(err, sshConfigSection.offset)...)
+		snippet.Blocks = blocks
+		for _, block := range snippet.Blocks {
+			if block.Kind == "" {
+				errs = append(errs, &ParseError{Line: sshConfigSection.offset + 1, Message: "properties must belong to a Host block"})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return snippet, nil
+}
+
+func offsetErrors(err error, offset int) ParseErrors {
+	errs, ok := err.(ParseErrors)
+	if !ok {
+		return nil
+	}
+
+	for _, e := range errs {
+		e.Line += offset
+	}
+
+	return errs
+}
+
+// ExtractSnippet returns all hosts file entries and ssh config blocks of alias; hosts may be nil
+func ExtractSnippet(hosts *Hosts, sshConfig *SSHConfig, alias string) *Snippet {
+	snippet := &Snippet{Blocks: sshConfig.FindBlocks(alias)}
+	if hosts != nil {
+		snippet.Hosts = hosts.FindEntries(alias)
+	}
+
+	return snippet
+}
+
+// ApplySnippet splices the sections of updated into hosts and sshConfig at the positions of the entries of original.
+// A section missing in updated removes all entries of original in that file.
+func ApplySnippet(hosts *Hosts, sshConfig *SSHConfig, original *Snippet, updated *Snippet) error {
+	if hosts != nil {
+		hosts.ReplaceEntries(original.Hosts, updated.Hosts)
+	} else if len(updated.Hosts) > 0 {
+		return fmt.Errorf("Failed to apply hosts file entries: hosts file not loaded")
+	}
+
+	sshConfig.ReplaceBlocks(original.Blocks, updated.Blocks)
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package files
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSnippet(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		hosts      []string
+		blocks     []string
+		errorLines []int
+	}{
+		{
+			name:    "both sections",
+			content: "# Editing entries of 'web01'\n\n# --- hosts file ---\n10.0.0.1 web01 # primary\n\n# --- ssh config ---\nHost web01\n  HostName 10.0.0.1\n",
+			hosts:   []string{"10.0.0.1 web01 # primary"},
+			blocks:  []string{"Host web01\n  HostName 10.0.0.1\n"},
+		},
+		{
+			name:    "ssh config only",
+			content: "# --- ssh config ---\nHost web01\n  User admin\n\nHost web01.lab\n  User root\n",
+			blocks:  []string{"Host web01\n  User admin\n", "Host web01.lab\n  User root\n"},
+		},
+		{
+			name:    "empty sections",
+			content: "# --- hosts file ---\n\n# --- ssh config ---\n",
+			hosts:   []string{},
+			blocks:  []string{},
+		},
+		{
+			name:    "disabled entries",
+			content: "# --- hosts file ---\n#[disabled] 10.0.0.1 web01\n# --- ssh config ---\n#[disabled] Host web01\n#[disabled]   HostName 10.0.0.1\n",
+			hosts:   []string{"#[disabled] 10.0.0.1 web01"},
+			blocks:  []string{"#[disabled] Host web01\n#[disabled]   HostName 10.0.0.1\n"},
+		},
+		{
+			name:       "content outside of sections",
+			content:    "10.0.0.1 web01\n# --- ssh config ---\nHost web01\n",
+			errorLines: []int{1},
+		},
+		{
+			name:       "invalid hosts entries",
+			content:    "# --- hosts file ---\n10.0.0.1 web01\n10.0.0.x! web02\n10.0.0.3\n",
+			errorLines: []int{3, 4},
+		},
+		{
+			name:       "invalid ssh config",
+			content:    "# --- hosts file ---\n10.0.0.1 web01\n# --- ssh config ---\nHost web01\n  Port abc\n",
+			errorLines: []int{5},
+		},
+		{
+			name:       "properties without host block",
+			content:    "# --- ssh config ---\n  User admin\nHost web01\n",
+			errorLines: []int{2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snippet, err := ParseSnippet(test.content)
+			if test.errorLines != nil {
+				var errs ParseErrors
+				if !errors.As(err, &errs) {
+					t.Fatalf("got error %v, want ParseErrors", err)
+				}
+				lines := make([]int, len(errs))
+				for i, e := range errs {
+					lines[i] = e.Line
+				}
+				if !equalInts(lines, test.errorLines) {
+					t.Errorf("got errors on lines %v, want %v", lines, test.errorLines)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if (snippet.Hosts == nil) != (test.hosts == nil) {
+				t.Fatalf("got hosts section %v, want %v", snippet.Hosts != nil, test.hosts != nil)
+			}
+			if got := hostStrings(snippet.Hosts); strings.Join(got, "\n") != strings.Join(test.hosts, "\n") {
+				t.Errorf("got hosts %q, want %q", got, test.hosts)
+			}
+
+			if (snippet.Blocks == nil) != (test.blocks == nil) {
+				t.Fatalf("got ssh config section %v, want %v", snippet.Blocks != nil, test.blocks != nil)
+			}
+			if got := blockStrings(snippet.Blocks); strings.Join(got, "\n") != strings.Join(test.blocks, "\n") {
+				t.Errorf("got blocks %q, want %q", got, test.blocks)
+			}
+		})
+	}
+}
+
+func TestSnippetRoundTrip(t *testing.T) {
+	hosts := testHosts(t, "10.0.0.1 web01 web01.lab # primary\n10.0.0.2 db01\n10.0.0.3 web01\n")
+	sshConfig := testSSHConfig(t, "Host web01\n  HostName 10.0.0.1\n  # hosts-cli: managed=true\n\nHost db01\n  HostName 10.0.0.2\n")
+
+	snippet := ExtractSnippet(hosts, sshConfig, "web01")
+	parsed, err := ParseSnippet(snippet.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := parsed.String(), snippet.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := parsed.Aliases(), []string{"web01", "web01.lab"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got aliases %q, want %q", got, want)
+	}
+}
+
+func TestApplySnippet(t *testing.T) {
+	tests := []struct {
+		name       string
+		hosts      string
+		sshConfig  string
+		edited     string
+		wantHosts  string
+		wantConfig string
+	}{
+		{
+			name:       "edit in place",
+			hosts:      "10.0.0.1 web01\n10.0.0.2 db01\n",
+			sshConfig:  "Host db00\n  User root\n\nHost web01\n  User admin\n\nHost db01\n  User root\n",
+			edited:     "# --- hosts file ---\n10.0.0.9 web01\n# --- ssh config ---\nHost web01\n  User deploy\n",
+			wantHosts:  "10.0.0.9 web01\n10.0.0.2 db01",
+			wantConfig: "Host db00\n  User root\n\nHost web01\n  User deploy\n\nHost db01\n  User root\n",
+		},
+		{
+			name:       "remove and add entries",
+			hosts:      "10.0.0.1 web01\n10.0.0.2 db01\n10.0.0.3 web01\n",
+			sshConfig:  "Host web01\n  User admin\n",
+			edited:     "# --- hosts file ---\n10.0.0.3 web01\n10.0.0.4 web01.lab\n# --- ssh config ---\nHost web01\n  User admin\n\nHost web01.lab\n  User admin\n",
+			wantHosts:  "10.0.0.3 web01\n10.0.0.4 web01.lab\n10.0.0.2 db01",
+			wantConfig: "Host web01\n  User admin\n\nHost web01.lab\n  User admin\n",
+		},
+		{
+			name:       "empty sections",
+			hosts:      "10.0.0.1 web01\n10.0.0.2 db01\n",
+			sshConfig:  "Host web01\n  User admin\n\nHost db01\n  User root\n",
+			edited:     "# --- hosts file ---\n# --- ssh config ---\n",
+			wantHosts:  "10.0.0.2 db01",
+			wantConfig: "Host db01\n  User root\n",
+		},
+		{
+			name:       "missing hosts section",
+			hosts:      "10.0.0.1 web01\n10.0.0.2 db01\n",
+			sshConfig:  "Host web01\n  User admin\n",
+			edited:     "# --- ssh config ---\nHost web01\n  User deploy\n",
+			wantHosts:  "10.0.0.2 db01",
+			wantConfig: "Host web01\n  User deploy\n",
+		},
+		{
+			name:       "missing ssh config section",
+			hosts:      "10.0.0.1 web01\n",
+			sshConfig:  "Host web01\n  User admin\n\nHost db01\n  User root\n",
+			edited:     "# --- hosts file ---\n10.0.0.1 web01\n",
+			wantHosts:  "10.0.0.1 web01",
+			wantConfig: "Host db01\n  User root\n",
+		},
+		{
+			name:       "all sections deleted",
+			hosts:      "10.0.0.1 web01\n10.0.0.2 db01\n",
+			sshConfig:  "Host web01\n  User admin\n\nHost db01\n  User root\n",
+			edited:     "# Editing entries of 'web01'\n",
+			wantHosts:  "10.0.0.2 db01",
+			wantConfig: "Host db01\n  User root\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hosts := testHosts(t, test.hosts)
+			sshConfig := testSSHConfig(t, test.sshConfig)
+
+			original := ExtractSnippet(hosts, sshConfig, "web01")
+			updated, err := ParseSnippet(test.edited)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := ApplySnippet(hosts, sshConfig, original, updated); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := strings.Join(hostStrings(hosts.entries), "\n"); got != test.wantHosts {
+				t.Errorf("got hosts\n%s\nwant\n%s", got, test.wantHosts)
+			}
+			if got := strings.Join(blockStrings(sshConfig.blocks), "\n"); got != test.wantConfig {
+				t.Errorf("got ssh config\n%s\nwant\n%s", got, test.wantConfig)
+			}
+		})
+	}
+}
+
+func TestApplySnippetWithoutHostsFile(t *testing.T) {
+	sshConfig := testSSHConfig(t, "Host web01\n  User admin\n")
+	original := ExtractSnippet(nil, sshConfig, "web01")
+
+	updated, err := ParseSnippet("# --- hosts file ---\n10.0.0.1 web01\n# --- ssh config ---\nHost web01\n  User admin\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ApplySnippet(nil, sshConfig, original, updated); err == nil {
+		t.Errorf("expected an error for hosts file entries without hosts file")
+	}
+}
+
+func hostStrings(entries []*Host) []string {
+	output := make([]string, len(entries))
+	for i, entry := range entries {
+		output[i] = entry.String()
+	}
+
+	return output
+}
+
+func blockStrings(blocks []*HostBlock) []string {
+	output := make([]string, len(blocks))
+	for i, block := range blocks {
+		output[i] = block.String()
+	}
+
+	return output
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,280 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/martinnirtl/hosts-cli/internal/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+// specMetaKey marks entries written by 'hosts apply' with the name of their spec, e.g. spec=lab
+const specMetaKey = "spec"
+
+// Spec declares the desired hosts file entries and ssh config blocks, e.g.
+//
+//	name: lab
+//	hosts:
+//	  - address: 10.0.1.10
+//	    aliases: [node-0, node-0.lab]
+//	ssh:
+//	  - hosts: [node-0]
+//	    options:
+//	      HostName: 10.0.1.10
+//	      User: admin
+type Spec struct {
+	Name  string       `json:"name" yaml:"name" toml:"name"`
+	Hosts []*SpecHost  `json:"hosts" yaml:"hosts" toml:"hosts"`
+	SSH   []*SpecBlock `json:"ssh" yaml:"ssh" toml:"ssh"`
+
+	filepath string
+}
+
+type SpecHost struct {
+	Address string   `json:"address" yaml:"address" toml:"address"`
+	Aliases []string `json:"aliases" yaml:"aliases" toml:"aliases"`
+	Tags    []string `json:"tags" yaml:"tags" toml:"tags"`
+}
+
+type SpecBlock struct {
+	Hosts   []string               `json:"hosts" yaml:"hosts" toml:"hosts"`
+	Options map[string]interface{} `json:"options" yaml:"options" toml:"options"`
+	Tags    []string               `json:"tags" yaml:"tags" toml:"tags"`
+}
+
+func (spec *Spec) Filepath() string {
+	return spec.filepath
+}
+
+func (spec *Spec) Read() error {
+	content, err := os.ReadFile(spec.filepath)
+	if err != nil {
+		return fmt.Errorf("Failed to open '%s': %v", spec.filepath, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(spec.filepath)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(spec)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(spec)
+	case ".toml":
+		var meta toml.MetaData
+		meta, err = toml.Decode(string(content), spec)
+		if err == nil && len(meta.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown field '%s'", meta.Undecoded()[0])
+		}
+	default:
+		return fmt.Errorf("Failed to read '%s': unsupported file type, use .yaml, .json or .toml", spec.filepath)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed parsing '%s': %v", spec.filepath, err)
+	}
+
+	if spec.Name == "" {
+		spec.Name = strings.TrimSuffix(filepath.Base(spec.filepath), filepath.Ext(spec.filepath))
+	}
+
+	return spec.Validate()
+}
+
+// Validate reports all invalid declarations of the spec at once
+func (spec *Spec) Validate() error {
+	problems := make([]string, 0)
+	if !helpers.IsValidTag(spec.Name) {
+		problems = append(problems, fmt.Sprintf("name '%s' must only contain letters, digits, '.', '_' and '-'", spec.Name))
+	}
+
+	primaries := make([]string, 0, len(spec.Hosts))
+	for i, host := range spec.Hosts {
+		if net.ParseIP(host.Address) == nil {
+			problems = append(problems, fmt.Sprintf("hosts[%d]: address '%s' is not an IP address", i, host.Address))
+		}
+		if len(host.Aliases) == 0 {
+			problems = append(problems, fmt.Sprintf("hosts[%d]: at least one alias is required", i))
+		} else if helpers.SliceContains(primaries, host.Aliases[0]) {
+			problems = append(problems, fmt.Sprintf("hosts[%d]: first alias '%s' is declared more than once", i, host.Aliases[0]))
+		} else {
+			primaries = append(primaries, host.Aliases[0])
+		}
+		for _, alias := range host.Aliases {
+			if !helpers.IsValidHostname(alias) {
+				problems = append(problems, fmt.Sprintf("hosts[%d]: '%s' is not a valid host name", i, alias))
+			}
+		}
+		problems = append(problems, invalidTags(fmt.Sprintf("hosts[%d]", i), host.Tags)...)
+	}
+
+	primaries = primaries[:0]
+	for i, block := range spec.SSH {
+		if len(block.Hosts) == 0 {
+			problems = append(problems, fmt.Sprintf("ssh[%d]: at least one host is required", i))
+		} else if helpers.SliceContains(primaries, block.Hosts[0]) {
+			problems = append(problems, fmt.Sprintf("ssh[%d]: first host '%s' is declared more than once", i, block.Hosts[0]))
+		} else {
+			primaries = append(primaries, block.Hosts[0])
+		}
+		declared := make(map[string]string, len(block.Options))
+		for _, keyword := range sortedKeywords(block.Options) {
+			canonical, ok := CanonicalKeyword(keyword)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("ssh[%d]: unknown option '%s'", i, keyword))
+				continue
+			} else if canonical == "Host" || canonical == "Match" || canonical == "Include" {
+				problems = append(problems, fmt.Sprintf("ssh[%d]: option '%s' is not allowed", i, keyword))
+				continue
+			}
+			if other, ok := declared[canonical]; ok {
+				problems = append(problems, fmt.Sprintf("ssh[%d]: options '%s' and '%s' both declare %s", i, other, keyword, canonical))
+				continue
+			}
+			declared[canonical] = keyword
+
+			values, err := optionValues(block.Options[keyword])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("ssh[%d]: option '%s' %v", i, keyword, err))
+				continue
+			}
+			for _, value := range values {
+				if err := ValidateSSHConfig(canonical + " " + value); err != nil {
+					problems = append(problems, fmt.Sprintf("ssh[%d]: option '%s': %s", i, keyword, strings.TrimPrefix(err.Error(), "line 1: ")))
+				}
+			}
+		}
+		problems = append(problems, invalidTags(fmt.Sprintf("ssh[%d]", i), block.Tags)...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid spec '%s':\n  %s", spec.filepath, strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+func invalidTags(path string, tags []string) []string {
+	problems := make([]string, 0)
+	for _, tag := range tags {
+		if !helpers.IsValidTag(tag) {
+			problems = append(problems, fmt.Sprintf("%s: '%s' is not a valid tag", path, tag))
+		}
+	}
+
+	return problems
+}
+
+// Entries returns the hosts file entries declared by the spec, marked as written by it
+func (spec *Spec) Entries() []*Host {
+	entries := make([]*Host, len(spec.Hosts))
+	for i, declared := range spec.Hosts {
+		entries[i] = &Host{address: declared.Address, aliases: declared.Aliases}
+		entries[i].SetManaged(true)
+		entries[i].SetMeta(specMetaKey, spec.Name)
+		entries[i].AddTags(declared.Tags...)
+	}
+
+	return entries
+}
+
+// Blocks returns the ssh config Host blocks declared by the spec, marked as written by it. HostName is
+// written first, all other options in alphabetical order. Lists of values are written as one property per value.
+func (spec *Spec) Blocks() []*HostBlock {
+	blocks := make([]*HostBlock, len(spec.SSH))
+	for i, declared := range spec.SSH {
+		keywords := make([]string, 0, len(declared.Options))
+		values := make(map[string][]string, len(declared.Options))
+		for keyword, value := range declared.Options {
+			canonical, _ := CanonicalKeyword(keyword)
+			keywords = append(keywords, canonical)
+			values[canonical], _ = optionValues(value)
+		}
+		sort.Slice(keywords, func(i, j int) bool {
+			if keywords[i] == "HostName" || keywords[j] == "HostName" {
+				return keywords[i] == "HostName"
+			}
+
+			return keywords[i] < keywords[j]
+		})
+
+		block := &HostBlock{Kind: "Host", Hosts: declared.Hosts, Props: make([]*HostBlockProp, 0, len(keywords))}
+		for _, keyword := range keywords {
+			for _, value := range values[keyword] {
+				block.Props = append(block.Props, &HostBlockProp{Kind: keyword, Value: value})
+			}
+		}
+		block.SetManaged(true)
+		block.SetMeta(specMetaKey, spec.Name)
+		block.AddTags(declared.Tags...)
+
+		blocks[i] = block
+	}
+
+	return blocks
+}
+
+func sortedKeywords(options map[string]interface{}) []string {
+	keywords := make([]string, 0, len(options))
+	for keyword := range options {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	return keywords
+}
+
+// optionValues returns the values of an option decoded from YAML, JSON or TOML, which is either a single value
+// or a list of values, e.g. several IdentityFile or LocalForward
+func optionValues(value interface{}) ([]string, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		list = []interface{}{value}
+	} else if len(list) == 0 {
+		return nil, fmt.Errorf("must not be an empty list")
+	}
+
+	values := make([]string, len(list))
+	for i, item := range list {
+		switch item.(type) {
+		case []interface{}, map[string]interface{}, nil:
+			return nil, fmt.Errorf("must be a value or a list of values")
+		}
+		values[i] = optionValue(item)
+	}
+
+	return values, nil
+}
+
+// optionValue formats a value decoded from YAML, JSON or TOML the way ssh_config expects it, e.g. true -> yes
+func optionValue(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func GetSpec(filepath string) (*Spec, error) {
+	spec := &Spec{
+		filepath: filepath,
+	}
+
+	err := spec.Read()
+
+	return spec, err
+}
@@ -0,0 +1,186 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		problem string
+	}{
+		{"valid", map[string]interface{}{"HostName": "10.0.0.1", "Port": 2222, "ForwardAgent": true}, ""},
+		{"list", map[string]interface{}{"IdentityFile": []interface{}{"~/.ssh/a", "~/.ssh/b"}}, ""},
+		{"json number", map[string]interface{}{"Port": float64(2222)}, ""},
+		{"bad port", map[string]interface{}{"Port": 1000000}, "option 'Port': bad port '1000000'"},
+		{"bad port in list", map[string]interface{}{"Port": []interface{}{22, 0}}, "option 'Port': bad port '0'"},
+		{"empty value", map[string]interface{}{"User": ""}, "option 'User': missing argument for 'User'"},
+		{"empty list", map[string]interface{}{"IdentityFile": []interface{}{}}, "option 'IdentityFile' must not be an empty list"},
+		{"nested list", map[string]interface{}{"LocalForward": []interface{}{[]interface{}{"8080", "localhost:80"}}}, "option 'LocalForward' must be a value or a list of values"},
+		{"map", map[string]interface{}{"User": map[string]interface{}{"name": "admin"}}, "option 'User' must be a value or a list of values"},
+		{"unknown", map[string]interface{}{"Colour": "blue"}, "unknown option 'Colour'"},
+		{"not allowed", map[string]interface{}{"Match": "all"}, "option 'Match' is not allowed"},
+		{"differing case", map[string]interface{}{"Port": 22, "port": 2222}, "options 'Port' and 'port' both declare Port"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := &Spec{Name: "lab", SSH: []*SpecBlock{{Hosts: []string{"web01"}, Options: test.options}}}
+
+			err := spec.Validate()
+			if test.problem == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "ssh[0]: "+test.problem) {
+				t.Fatalf("got %v, want problem %q", err, test.problem)
+			}
+		})
+	}
+}
+
+func TestSpecBlocks(t *testing.T) {
+	spec := &Spec{Name: "lab", SSH: []*SpecBlock{{
+		Hosts: []string{"web01", "web01.lab"},
+		Options: map[string]interface{}{
+			"user":         "admin",
+			"LocalForward": []interface{}{"8080 localhost:80", "8443 localhost:443"},
+			"IdentityFile": []interface{}{"~/.ssh/a", "~/.ssh/b"},
+			"HostName":     "10.0.0.1",
+			"ForwardAgent": false,
+			"Port":         float64(2222),
+		},
+		Tags: []string{"web"},
+	}}}
+
+	want := `Host web01 web01.lab
+  # hosts-cli: managed=true spec=lab tags=web
+  HostName 10.0.0.1
+  ForwardAgent no
+  IdentityFile ~/.ssh/a
+  IdentityFile ~/.ssh/b
+  LocalForward 8080 localhost:80
+  LocalForward 8443 localhost:443
+  Port 2222
+  User admin
+`
+	if got := spec.Blocks()[0].String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func labSpec(aliases ...string) *Spec {
+	spec := &Spec{Name: "lab"}
+	for i, alias := range aliases {
+		address := "10.0.1." + string(rune('1'+i))
+		spec.Hosts = append(spec.Hosts, &SpecHost{Address: address, Aliases: []string{alias}})
+		spec.SSH = append(spec.SSH, &SpecBlock{
+			Hosts:   []string{alias},
+			Options: map[string]interface{}{"HostName": address, "IdentityFile": []interface{}{"~/.ssh/a", "~/.ssh/b"}},
+		})
+	}
+
+	return spec
+}
+
+// applySpec applies spec to the given files and returns them re-read from their written content
+func applySpec(t *testing.T, spec *Spec, hosts *Hosts, sshConfig *SSHConfig, prune bool) (*Hosts, *SSHConfig) {
+	t.Helper()
+
+	plan, err := PlanSpec(spec, hosts, sshConfig, prune, false)
+	if err != nil {
+		t.Fatalf("planning spec: %v", err)
+	}
+	plan.Apply(hosts, sshConfig)
+
+	return testHosts(t, hosts.String()), testSSHConfig(t, sshConfig.String())
+}
+
+func TestPlanSpec(t *testing.T) {
+	t.Run("idempotent", func(t *testing.T) {
+		spec := labSpec("node-0", "node-1")
+		hosts, sshConfig := applySpec(t, spec, testHosts(t, "127.0.0.1 localhost\n"), testSSHConfig(t, "Host *\n  User admin\n"), false)
+
+		plan, err := PlanSpec(spec, hosts, sshConfig, true, false)
+		if err != nil {
+			t.Fatalf("planning spec again: %v", err)
+		}
+		if !plan.Empty() {
+			t.Errorf("second plan is not empty: %+v %+v", plan.Hosts, plan.Blocks)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		hosts, sshConfig := applySpec(t, labSpec("node-0"), testHosts(t, ""), testSSHConfig(t, ""), false)
+
+		spec := labSpec("node-0")
+		spec.SSH[0].Options["User"] = "root"
+		plan, err := PlanSpec(spec, hosts, sshConfig, false, false)
+		if err != nil {
+			t.Fatalf("planning spec: %v", err)
+		}
+		if created, updated, destroyed := plan.Counts(); created != 0 || updated != 1 || destroyed != 0 {
+			t.Errorf("got %d to add, %d to change, %d to destroy, want 0, 1, 0", created, updated, destroyed)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		prune      bool
+		destroyed  int
+		undeclared int
+		remaining  []string
+	}{
+		{name: "keep undeclared", prune: false, destroyed: 0, undeclared: 2, remaining: []string{"node-0", "node-1"}},
+		{name: "prune undeclared", prune: true, destroyed: 2, undeclared: 0, remaining: []string{"node-0"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hosts, sshConfig := applySpec(t, labSpec("node-0", "node-1"), testHosts(t, ""), testSSHConfig(t, ""), false)
+
+			plan, err := PlanSpec(labSpec("node-0"), hosts, sshConfig, test.prune, false)
+			if err != nil {
+				t.Fatalf("planning spec: %v", err)
+			}
+			if _, _, destroyed := plan.Counts(); destroyed != test.destroyed {
+				t.Errorf("got %d to destroy, want %d", destroyed, test.destroyed)
+			}
+			if plan.Undeclared != test.undeclared {
+				t.Errorf("got %d undeclared, want %d", plan.Undeclared, test.undeclared)
+			}
+
+			plan.Apply(hosts, sshConfig)
+			hosts, sshConfig = testHosts(t, hosts.String()), testSSHConfig(t, sshConfig.String())
+			for _, lists := range [][][]string{hosts.ListHosts(), sshConfig.ListHosts()} {
+				names := make([]string, 0, len(lists))
+				for _, list := range lists {
+					names = append(names, list[0])
+				}
+				if strings.Join(names, " ") != strings.Join(test.remaining, " ") {
+					t.Errorf("got %v, want %v", names, test.remaining)
+				}
+			}
+		})
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		sshConfig := testSSHConfig(t, "Host node-0\n  HostName 10.9.9.9\n")
+
+		if _, err := PlanSpec(labSpec("node-0"), nil, sshConfig, false, false); err == nil {
+			t.Fatal("expected conflict with unmanaged Host block")
+		}
+
+		plan, err := PlanSpec(labSpec("node-0"), nil, sshConfig, false, true)
+		if err != nil {
+			t.Fatalf("adopting: %v", err)
+		}
+		if created, _, destroyed := plan.Counts(); created != 1 || destroyed != 1 {
+			t.Errorf("got %d to add and %d to destroy, want 1 and 1", created, destroyed)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/martinnirtl/hosts-cli/internal/helpers"
 )
@@ -17,9 +18,11 @@ type SSHConfig struct {
 }
 
 type HostBlock struct {
-	Kind  string // Host or Match
-	Hosts []string
-	Props []*HostBlockProp
+	Kind     string // Host or Match
+	Hosts    []string
+	Props    []*HostBlockProp
+	Disabled bool
+	meta     Metadata
 }
 
 type HostBlockProp struct {
@@ -42,15 +45,147 @@ func (prop *HostBlockProp) String() string {
 }
 
 func (block *HostBlock) String() string {
+	if block.Kind == "" {
+		output := ""
+		for _, prop := range block.Props {
+			output = output + strings.TrimLeft(prop.String(), " ") + "\n"
+		}
+
+		return output
+	}
+
 	output := fmt.Sprintf("%s %s\n", block.Kind, strings.Join(block.Hosts, " "))
+	if len(block.meta) > 0 {
+		output = output + "  # " + block.meta.String() + "\n"
+	}
 
 	for _, prop := range block.Props {
 		output = output + prop.String() + "\n"
 	}
 
+	if block.Disabled {
+		output = disabledMarker + " " + strings.ReplaceAll(strings.TrimSuffix(output, "\n"), "\n", "\n"+disabledMarker+" ") + "\n"
+	}
+
 	return output
 }
 
+func (block *HostBlock) GetMeta(key string) string {
+	return getMeta(block.meta, key)
+}
+
+func (block *HostBlock) SetMeta(key string, value string) {
+	block.meta = setMeta(block.meta, key, value)
+}
+
+// ClearMeta removes all metadata of the entry, e.g. before storing it in a profile
+func (block *HostBlock) ClearMeta() {
+	block.meta = nil
+}
+
+// Managed reports whether the entry was created by the CLI
+func (block *HostBlock) Managed() bool {
+	return getMeta(block.meta, managedMetaKey) == "true"
+}
+
+func (block *HostBlock) SetManaged(managed bool) {
+	block.meta = setManaged(block.meta, managed)
+}
+
+// Expires returns the expiry time set by 'hosts add --ttl' if any
+func (block *HostBlock) Expires() (time.Time, bool) {
+	return getExpires(block.meta)
+}
+
+func (block *HostBlock) SetExpires(expires time.Time) {
+	block.meta = setExpires(block.meta, expires)
+}
+
+func (block *HostBlock) Expired(now time.Time) bool {
+	return isExpired(block.meta, now)
+}
+
+func (block *HostBlock) Tags() []string {
+	return getList(block.meta, tagsMetaKey)
+}
+
+func (block *HostBlock) HasTag(tag string) bool {
+	return helpers.SliceContains(block.Tags(), tag)
+}
+
+func (block *HostBlock) AddTags(tags ...string) {
+	block.meta = addToList(block.meta, tagsMetaKey, tags)
+}
+
+// Owners returns the owners sharing the entry as registered by 'hosts add --owner'
+func (block *HostBlock) Owners() []string {
+	return getOwners(block.meta)
+}
+
+func (block *HostBlock) HasOwner(owner string) bool {
+	return helpers.SliceContains(block.Owners(), owner)
+}
+
+// AddOwner registers owner, which is released by 'hosts gc' after expires unless it is zero
+func (block *HostBlock) AddOwner(owner string, expires time.Time) {
+	block.meta = addOwner(block.meta, owner, expires)
+}
+
+// RemoveOwner releases the entry by owner and returns the number of remaining owners
+func (block *HostBlock) RemoveOwner(owner string) int {
+	block.meta = removeOwner(block.meta, owner)
+
+	return len(block.Owners())
+}
+
+// ReleaseExpiredOwners releases the entry by all owners whose expiry has passed and returns them
+func (block *HostBlock) ReleaseExpiredOwners(now time.Time) []string {
+	var released []string
+	block.meta, released = releaseExpiredOwners(block.meta, now)
+
+	return released
+}
+
+// GetProp returns the value of the first property of the given kind
+func (block *HostBlock) GetProp(kind string) (string, bool) {
+	for _, prop := range block.Props {
+		if strings.EqualFold(prop.Kind, kind) {
+			return prop.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// SetProp updates the value of an existing property or appends a new one
+func (block *HostBlock) SetProp(kind string, value string) {
+	for _, prop := range block.Props {
+		if strings.EqualFold(prop.Kind, kind) {
+			prop.Kind = kind
+			prop.Value = value
+
+			return
+		}
+	}
+
+	block.Props = append(block.Props, &HostBlockProp{Kind: kind, Value: value})
+}
+
+// RemoveProp removes all properties of the given kind and reports whether any were found
+func (block *HostBlock) RemoveProp(kind string) bool {
+	props := make([]*HostBlockProp, 0, len(block.Props))
+	for _, prop := range block.Props {
+		if !strings.EqualFold(prop.Kind, kind) {
+			props = append(props, prop)
+		}
+	}
+
+	removed := len(props) != len(block.Props)
+	block.Props = props
+
+	return removed
+}
+
 func (sshConfig *SSHConfig) String() string {
 	stringifiedBlocks := make([]string, len(sshConfig.blocks))
 
@@ -61,6 +196,10 @@ func (sshConfig *SSHConfig) String() string {
 	return strings.Join(stringifiedBlocks, "\n")
 }
 
+func (sshConfig *SSHConfig) Bytes() []byte {
+	return []byte(sshConfig.String() + "\n")
+}
+
 func (sshConfig *SSHConfig) Read() error {
 	file, err := os.Open(sshConfig.filepath)
 	if err != nil {
@@ -68,27 +207,56 @@ func (sshConfig *SSHConfig) Read() error {
 	}
 	defer file.Close()
 
-	rd := bufio.NewReader(file)
+	// invalid lines are kept as they are, the file may have been written by hand or other tools
+	blocks, err := parseSSHConfig(file)
+	if _, invalid := err.(ParseErrors); err != nil && !invalid {
+		return err
+	}
+	sshConfig.blocks = append(sshConfig.blocks, blocks...)
+
+	return nil
+}
+
+// parseSSHConfig returns all blocks of r and ParseErrors for all invalid lines, which are parsed nevertheless
+func parseSSHConfig(r io.Reader) ([]*HostBlock, error) {
+	blocks := make([]*HostBlock, 0, 10)
+	errs := make(ParseErrors, 0)
+
+	rd := bufio.NewReader(r)
 
 	var currentBlock *HostBlock
-	for {
+	for lineNumber := 1; ; lineNumber++ {
 		line, err := rd.ReadString('\n')
 		if err != nil && err != io.EOF {
-			return fmt.Errorf("Failed reading file: %v", err)
+			return nil, fmt.Errorf("Failed reading file: %v", err)
 		} else if err == io.EOF && len(line) == 0 {
 			break
 		}
 
-		fields := strings.Fields(line)
-		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		line, disabled := cutDisabledMarker(line)
+
+		key, value := splitKeyValue(line)
+		if strings.HasPrefix(key, "#") && currentBlock != nil && currentBlock.Kind != "" {
+			if _, meta := cutMetadata(strings.TrimSpace(line)[1:]); meta != nil {
+				currentBlock.meta = meta
+				continue
+			}
+		}
+		if key == "" || strings.HasPrefix(key, "#") {
 			continue // skip empty lines and comments
 		} // TODO think about keeping empty lines and comments. use an interface with field Kind to identify blockType.. make SSHConfig hold array of interface
 
-		key := fields[0]
+		if keyword, ok := CanonicalKeyword(key); !ok {
+			errs = append(errs, &ParseError{Line: lineNumber, Message: fmt.Sprintf("unknown keyword '%s'", key)})
+		} else if value == "" {
+			errs = append(errs, &ParseError{Line: lineNumber, Message: fmt.Sprintf("missing argument for '%s'", keyword)})
+		} else if keyword == "Port" && !helpers.IsValidPort(value) {
+			errs = append(errs, &ParseError{Line: lineNumber, Message: fmt.Sprintf("bad port '%s'", value)})
+		}
 
 		switch strings.ToUpper(key) {
 		case "HOST", "MATCH":
-			currentBlock = &HostBlock{Kind: key, Hosts: fields[1:]}
+			currentBlock = &HostBlock{Kind: key, Hosts: strings.Fields(value), Disabled: disabled}
 			// if len(dropList) > 0 {
 			// 	for _, host := range fields[1:] {
 			// 		if helpers.SliceContains(dropList, host) {
@@ -97,27 +265,58 @@ func (sshConfig *SSHConfig) Read() error {
 			// 	}
 			// }
 
-			sshConfig.blocks = append(sshConfig.blocks, currentBlock)
+			blocks = append(blocks, currentBlock)
 
 		default:
-			value := strings.Join(fields[1:], " ")
+			if currentBlock == nil {
+				// options before the first Host or Match apply globally
+				currentBlock = &HostBlock{Kind: ""}
+				blocks = append(blocks, currentBlock)
+			}
 			currentBlock.Props = append(currentBlock.Props, &HostBlockProp{Kind: key, Value: value})
 		}
 	}
 
-	return nil
+	if len(errs) > 0 {
+		return blocks, errs
+	}
+
+	return blocks, nil
+}
+
+func (sshConfig *SSHConfig) Filepath() string {
+	return sshConfig.filepath
+}
+
+func (sshConfig *SSHConfig) Blocks() []*HostBlock {
+	return sshConfig.blocks
+}
+
+// FindBlocks returns all Host blocks listing the given alias
+func (sshConfig *SSHConfig) FindBlocks(alias string) []*HostBlock {
+	found := make([]*HostBlock, 0, 1)
+	for _, block := range sshConfig.blocks {
+		if strings.ToUpper(block.Kind) == "HOST" && helpers.SliceContains(block.Hosts, alias) {
+			found = append(found, block)
+		}
+	}
+
+	return found
 }
 
 func (sshConfig *SSHConfig) ListHosts() [][]string {
-	list := make([][]string, len(sshConfig.blocks))
-	for i, entry := range sshConfig.blocks {
-		list[i] = entry.Hosts
+	list := make([][]string, 0, len(sshConfig.blocks))
+	for _, entry := range sshConfig.blocks {
+		if strings.ToUpper(entry.Kind) != "HOST" {
+			continue // skip Match blocks as they hold criteria instead of host names
+		}
+		list = append(list, entry.Hosts)
 	}
 
 	return list
 }
 
-func (sshConfig *SSHConfig) AddHost(hosts []string, hostname string, user string, identityFile string) error {
+func (sshConfig *SSHConfig) AddHost(hosts []string, hostname string, user string, identityFile string) (*HostBlock, error) {
 	configBlockProps := make([]*HostBlockProp, 0)
 	configBlockProps = append(configBlockProps, &HostBlockProp{Kind: "HostName", Value: hostname})
 	if user != "" {
@@ -158,7 +357,7 @@ func (sshConfig *SSHConfig) AddHost(hosts []string, hostname string, user string
 
 	sshConfig.blocks = append(sshConfig.blocks, configBlock)
 
-	return nil
+	return configBlock, nil
 }
 
 func (sshConfig *SSHConfig) RemoveHosts(hosts []string) []*HostBlock {
@@ -185,6 +384,154 @@ func (sshConfig *SSHConfig) RemoveHosts(hosts []string) []*HostBlock {
 	return removed
 }
 
+// RemoveBlock removes exactly the given block, leaving other blocks with the same hosts untouched
+func (sshConfig *SSHConfig) RemoveBlock(block *HostBlock) bool {
+	for i, b := range sshConfig.blocks {
+		if b == block {
+			sshConfig.blocks = append(sshConfig.blocks[:i], sshConfig.blocks[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetDisabled switches all Host blocks of alias off or on and returns the number of changed blocks. Blocks
+// shared with other hosts are split, so that those stay as they are.
+func (sshConfig *SSHConfig) SetDisabled(alias string, disabled bool) int {
+	changed := 0
+	for _, block := range sshConfig.FindBlocks(alias) {
+		if block.Disabled != disabled {
+			sshConfig.SplitHost(block, alias).Disabled = disabled
+			changed++
+		}
+	}
+
+	return changed
+}
+
+// RemoveBlocksFunc removes all blocks matching and returns them
+func (sshConfig *SSHConfig) RemoveBlocksFunc(match func(block *HostBlock) bool) []*HostBlock {
+	kept := make([]*HostBlock, 0, len(sshConfig.blocks))
+	removed := make([]*HostBlock, 0, 10)
+	for _, block := range sshConfig.blocks {
+		if match(block) {
+			removed = append(removed, block)
+		} else {
+			kept = append(kept, block)
+		}
+	}
+	sshConfig.blocks = kept
+
+	return removed
+}
+
+// RenameHost replaces host by newHost in the patterns of all Host blocks, including negated ones, and returns the number of changed blocks
+func (sshConfig *SSHConfig) RenameHost(host string, newHost string) int {
+	renamed := 0
+	for _, block := range sshConfig.blocks {
+		if strings.ToUpper(block.Kind) != "HOST" {
+			continue
+		}
+
+		changed := false
+		for i, pattern := range block.Hosts {
+			switch pattern {
+			case host:
+				block.Hosts[i] = newHost
+				changed = true
+			case "!" + host:
+				block.Hosts[i] = "!" + newHost
+				changed = true
+			}
+		}
+
+		if changed {
+			block.Hosts = helpers.UniqueStrings(block.Hosts)
+			renamed++
+		}
+	}
+
+	return renamed
+}
+
+// SplitHost moves host out of a block shared with other hosts into a copy of the block right after it and
+// returns the copy, so that changes to it keep the other hosts untouched. Blocks not listing other hosts are
+// returned as they are.
+func (sshConfig *SSHConfig) SplitHost(block *HostBlock, host string) *HostBlock {
+	others := make([]string, 0, len(block.Hosts))
+	shared := false
+	for _, pattern := range block.Hosts {
+		if pattern != host {
+			others = append(others, pattern)
+			shared = shared || !strings.HasPrefix(pattern, "!")
+		}
+	}
+	if !shared {
+		return block
+	}
+
+	split := &HostBlock{
+		Kind:     block.Kind,
+		Hosts:    []string{host},
+		Props:    make([]*HostBlockProp, len(block.Props)),
+		Disabled: block.Disabled,
+		meta:     block.meta.clone(),
+	}
+	for i, prop := range block.Props {
+		split.Props[i] = &HostBlockProp{Kind: prop.Kind, Value: prop.Value}
+	}
+	block.Hosts = others
+
+	for i, b := range sshConfig.blocks {
+		if b == block {
+			sshConfig.blocks = append(sshConfig.blocks[:i+1], append([]*HostBlock{split}, sshConfig.blocks[i+1:]...)...)
+			break
+		}
+	}
+
+	return split
+}
+
+// ReplaceBlocks puts replacements at the position of the first old block and drops the remaining old blocks
+func (sshConfig *SSHConfig) ReplaceBlocks(old []*HostBlock, replacements []*HostBlock) {
+	position := len(sshConfig.blocks)
+	if len(old) > 0 {
+		for i, block := range sshConfig.blocks {
+			if block == old[0] {
+				position = i
+				break
+			}
+		}
+	}
+
+	updated := make([]*HostBlock, 0, len(sshConfig.blocks)+len(replacements))
+	for i, block := range sshConfig.blocks {
+		if i == position {
+			updated = append(updated, replacements...)
+		}
+		if !containsBlock(old, block) {
+			updated = append(updated, block)
+		}
+	}
+	if position == len(sshConfig.blocks) {
+		updated = append(updated, replacements...)
+	}
+
+	sshConfig.blocks = updated
+}
+
+func containsBlock(blocks []*HostBlock, block *HostBlock) bool {
+	for _, b := range blocks {
+		if b == block {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (sshConfig *SSHConfig) Write() error {
 	file, err := os.OpenFile(sshConfig.filepath, os.O_RDWR, 0644)
 	if err != nil {
@@ -197,7 +544,7 @@ func (sshConfig *SSHConfig) Write() error {
 		return fmt.Errorf("Failed writing file '%s': %v", file.Name(), err)
 	}
 
-	_, err = file.WriteAt([]byte(sshConfig.String()+"\n"), 0)
+	_, err = file.WriteAt(sshConfig.Bytes(), 0)
 	if err != nil {
 		return fmt.Errorf("Failed writing file '%s': %v", file.Name(), err)
 	}
@@ -216,6 +563,21 @@ func GetSSHConfig(filepath string) (*SSHConfig, error) {
 	return sshConfig, err
 }
 
+// splitKeyValue splits lines like "HostName example.com" or "HostName=example.com" into keyword and argument
+func splitKeyValue(line string) (string, string) {
+	line = strings.TrimSpace(line)
+
+	end := strings.IndexAny(line, " \t=")
+	if end == -1 {
+		return line, ""
+	}
+
+	value := strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+
+	return line[:end], value
+}
+
 func negateHosts(hosts []string) []string {
 	negatedHosts := make([]string, len(hosts))
 	for i, h := range hosts {
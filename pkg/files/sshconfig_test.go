@@ -0,0 +1,157 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func testSSHConfig(t *testing.T, content string) *SSHConfig {
+	t.Helper()
+
+	blocks, err := parseSSHConfig(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parsing ssh config: %v", err)
+	}
+
+	return &SSHConfig{blocks: blocks}
+}
+
+func TestHostBlockString(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		want     string
+		disabled bool
+	}{
+		{"plain", "Host web01 web01.lab\n  HostName 10.0.0.1\n  User admin\n", "Host web01 web01.lab\n  HostName 10.0.0.1\n  User admin\n", false},
+		{"disabled", "#[disabled] Host web01\n#[disabled]   HostName 10.0.0.1\n", "#[disabled] Host web01\n#[disabled]   HostName 10.0.0.1\n", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sshConfig := testSSHConfig(t, test.content)
+			if len(sshConfig.blocks) != 1 {
+				t.Fatalf("got %d blocks, want 1", len(sshConfig.blocks))
+			}
+			if got := sshConfig.blocks[0].String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			if got := sshConfig.blocks[0].Disabled; got != test.disabled {
+				t.Errorf("got disabled %v, want %v", got, test.disabled)
+			}
+		})
+	}
+}
+
+func TestHostBlockProps(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		set     [2]string
+		remove  string
+		removed bool
+		want    string
+	}{
+		{
+			name:   "set new property",
+			config: "Host web01\n  HostName 10.0.0.1\n",
+			set:    [2]string{"Port", "2222"},
+			want:   "Host web01\n  HostName 10.0.0.1\n  Port 2222\n",
+		},
+		{
+			name:   "set existing property in canonical casing",
+			config: "Host web01\n  hostname 10.0.0.1\n  User admin\n",
+			set:    [2]string{"HostName", "10.0.0.2"},
+			want:   "Host web01\n  HostName 10.0.0.2\n  User admin\n",
+		},
+		{
+			name:    "remove all properties of a kind",
+			config:  "Host web01\n  IdentityFile ~/.ssh/a\n  User admin\n  identityfile ~/.ssh/b\n",
+			remove:  "IdentityFile",
+			removed: true,
+			want:    "Host web01\n  User admin\n",
+		},
+		{
+			name:   "remove missing property",
+			config: "Host web01\n  User admin\n",
+			remove: "Port",
+			want:   "Host web01\n  User admin\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block := testSSHConfig(t, test.config).blocks[0]
+			if test.set[0] != "" {
+				block.SetProp(test.set[0], test.set[1])
+			}
+			if test.remove != "" {
+				if got := block.RemoveProp(test.remove); got != test.removed {
+					t.Errorf("got removed %v, want %v", got, test.removed)
+				}
+			}
+			if got := block.String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitHost(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		host   string
+		split  bool
+		want   string
+	}{
+		{
+			name:   "shared block",
+			config: "Host web01 web02\n  # hosts-cli: profile=lab\n  HostName 10.0.0.1\n  User admin\nHost db01\n  HostName 10.0.0.9\n",
+			host:   "web01",
+			split:  true,
+			want:   "Host web02\n  # hosts-cli: profile=lab\n  HostName 10.0.0.1\n  User admin\n\nHost web01\n  # hosts-cli: profile=lab\n  HostName 10.0.0.2\n  User admin\n\nHost db01\n  HostName 10.0.0.9\n",
+		},
+		{
+			name:   "own block",
+			config: "Host web01\n  HostName 10.0.0.1\n",
+			host:   "web01",
+			want:   "Host web01\n  HostName 10.0.0.2\n",
+		},
+		{
+			name:   "negated patterns only",
+			config: "Host web01 !web02\n  HostName 10.0.0.1\n",
+			host:   "web01",
+			want:   "Host web01 !web02\n  HostName 10.0.0.2\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sshConfig := testSSHConfig(t, test.config)
+			block := sshConfig.FindBlocks(test.host)[0]
+
+			split := sshConfig.SplitHost(block, test.host)
+			if (split != block) != test.split {
+				t.Errorf("split is %v, want %v", split != block, test.split)
+			}
+			split.SetProp("HostName", "10.0.0.2")
+			if got := sshConfig.String(); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenameHost(t *testing.T) {
+	sshConfig := testSSHConfig(t, "Host web01 web02\n  User admin\n\nHost * !web01\n  User root\n\nMatch host web01\n  Port 2222\n\nHost web03 web01\n  Port 22\n")
+
+	if got := sshConfig.RenameHost("web01", "web03"); got != 3 {
+		t.Errorf("got %d renamed blocks, want 3", got)
+	}
+
+	want := "Host web03 web02\n  User admin\n\nHost * !web03\n  User root\n\nMatch host web01\n  Port 2222\n\nHost web03\n  Port 22\n"
+	if got := sshConfig.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
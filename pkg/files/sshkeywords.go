@@ -0,0 +1,124 @@
+package files
+
+import "strings"
+
+// sshKeywords holds all keywords of ssh_config(5) in their canonical casing
+var sshKeywords = []string{
+	"AddKeysToAgent",
+	"AddressFamily",
+	"BatchMode",
+	"BindAddress",
+	"BindInterface",
+	"CanonicalDomains",
+	"CanonicalizeFallbackLocal",
+	"CanonicalizeHostname",
+	"CanonicalizeMaxDots",
+	"CanonicalizePermittedCNAMEs",
+	"CASignatureAlgorithms",
+	"CertificateFile",
+	"ChannelTimeout",
+	"CheckHostIP",
+	"Ciphers",
+	"ClearAllForwardings",
+	"Compression",
+	"ConnectionAttempts",
+	"ConnectTimeout",
+	"ControlMaster",
+	"ControlPath",
+	"ControlPersist",
+	"DynamicForward",
+	"EnableEscapeCommandline",
+	"EnableSSHKeysign",
+	"EscapeChar",
+	"ExitOnForwardFailure",
+	"FingerprintHash",
+	"ForkAfterAuthentication",
+	"ForwardAgent",
+	"ForwardX11",
+	"ForwardX11Timeout",
+	"ForwardX11Trusted",
+	"GatewayPorts",
+	"GlobalKnownHostsFile",
+	"GSSAPIAuthentication",
+	"GSSAPIDelegateCredentials",
+	"HashKnownHosts",
+	"Host",
+	"HostbasedAcceptedAlgorithms",
+	"HostbasedAuthentication",
+	"HostKeyAlgorithms",
+	"HostKeyAlias",
+	"HostName",
+	"IdentitiesOnly",
+	"IdentityAgent",
+	"IdentityFile",
+	"IgnoreUnknown",
+	"Include",
+	"IPQoS",
+	"KbdInteractiveAuthentication",
+	"KbdInteractiveDevices",
+	"KexAlgorithms",
+	"KnownHostsCommand",
+	"LocalCommand",
+	"LocalForward",
+	"LogLevel",
+	"LogVerbose",
+	"MACs",
+	"Match",
+	"NoHostAuthenticationForLocalhost",
+	"NumberOfPasswordPrompts",
+	"ObscureKeystrokeTiming",
+	"PasswordAuthentication",
+	"PermitLocalCommand",
+	"PermitRemoteOpen",
+	"PKCS11Provider",
+	"Port",
+	"PreferredAuthentications",
+	"ProxyCommand",
+	"ProxyJump",
+	"ProxyUseFdpass",
+	"PubkeyAcceptedAlgorithms",
+	"PubkeyAuthentication",
+	"RekeyLimit",
+	"RemoteCommand",
+	"RemoteForward",
+	"RequestTTY",
+	"RequiredRSASize",
+	"RevokedHostKeys",
+	"SecurityKeyProvider",
+	"SendEnv",
+	"ServerAliveCountMax",
+	"ServerAliveInterval",
+	"SessionType",
+	"SetEnv",
+	"StdinNull",
+	"StreamLocalBindMask",
+	"StreamLocalBindUnlink",
+	"StrictHostKeyChecking",
+	"SyslogFacility",
+	"TCPKeepAlive",
+	"Tag",
+	"Tunnel",
+	"TunnelDevice",
+	"UpdateHostKeys",
+	"User",
+	"UserKnownHostsFile",
+	"VerifyHostKeyDNS",
+	"VisualHostKey",
+	"XAuthLocation",
+	// deprecated but still accepted
+	"ChallengeResponseAuthentication",
+	"PubkeyAcceptedKeyTypes",
+	"HostbasedKeyTypes",
+	"UseKeychain", // macOS only
+}
+
+// CanonicalKeyword returns the ssh_config keyword in its canonical casing, e.g. hostname -> HostName
+func CanonicalKeyword(keyword string) (string, bool) {
+	for _, k := range sshKeywords {
+		if strings.EqualFold(k, keyword) {
+			return k, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,46 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// StatePath returns the path of the copy of target as last written by the CLI, e.g. dir/%2Fetc%2Fhosts
+func StatePath(dir string, target string) string {
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+
+	return filepath.Join(dir, url.PathEscape(target))
+}
+
+// SaveState stores content as the last state of target written by the CLI
+func SaveState(dir string, target string, content []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("Failed to create directory '%s': %v", dir, err)
+	}
+
+	path := StatePath(dir, target)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("Failed writing file '%s': %v", path, err)
+	}
+
+	return nil
+}
+
+// LoadState returns the last state of target written by the CLI and whether there is one
+func LoadState(dir string, target string) ([]byte, bool, error) {
+	path := StatePath(dir, target)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("Failed to open '%s': %v", path, err)
+	}
+
+	return content, true, nil
+}
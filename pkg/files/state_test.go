@@ -0,0 +1,46 @@
+package files
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStatePath(t *testing.T) {
+	if got, want := StatePath("/state", "/etc/hosts"), filepath.Join("/state", "%2Fetc%2Fhosts"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	abs, err := filepath.Abs("hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := StatePath("/state", "hosts"), StatePath("/state", abs); got != want {
+		t.Errorf("got %q for relative path, want %q", got, want)
+	}
+}
+
+func TestSaveLoadState(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+
+	if _, ok, err := LoadState(dir, "/etc/hosts"); ok || err != nil {
+		t.Fatalf("got state %v, %v before saving, want none", ok, err)
+	}
+
+	for _, content := range []string{"10.0.0.1 web01\n", "10.0.0.2 db01\n"} {
+		if err := SaveState(dir, "/etc/hosts", []byte(content)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		state, ok, err := LoadState(dir, "/etc/hosts")
+		if err != nil || !ok {
+			t.Fatalf("got state %v, %v, want saved state", ok, err)
+		}
+		if string(state) != content {
+			t.Errorf("got %q, want %q", state, content)
+		}
+	}
+
+	if _, ok, _ := LoadState(dir, "/etc/ssh/ssh_config"); ok {
+		t.Errorf("got state for file that was never saved")
+	}
+}
@@ -0,0 +1,223 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Transaction stages new content for several files and writes all of them or, if writing one fails, none
+type Transaction struct {
+	changes      []*fileChange
+	backupSuffix string
+}
+
+type fileChange struct {
+	path     string
+	content  []byte
+	original []byte
+	existed  bool
+	backedUp bool
+	create   bool
+	perm     os.FileMode
+}
+
+func NewTransaction() *Transaction {
+	return &Transaction{
+		changes: make([]*fileChange, 0, 2),
+	}
+}
+
+// Stage sets the content path is replaced with on Commit; staging a path again replaces its staged content
+func (tx *Transaction) Stage(path string, content []byte) {
+	for _, change := range tx.changes {
+		if change.path == path {
+			change.content = content
+
+			return
+		}
+	}
+
+	tx.changes = append(tx.changes, &fileChange{path: path, content: content, perm: 0600})
+}
+
+// Create stages a new file written with perm on Commit, creating its directory if missing. Commit fails
+// without changing any file if path exists.
+func (tx *Transaction) Create(path string, content []byte, perm os.FileMode) {
+	tx.changes = append(tx.changes, &fileChange{path: path, content: content, create: true, perm: perm})
+}
+
+// Backup makes Commit copy each existing file to its path with suffix appended before replacing it
+func (tx *Transaction) Backup(suffix string) {
+	tx.backupSuffix = suffix
+}
+
+// Paths returns all staged paths in the order they are written
+func (tx *Transaction) Paths() []string {
+	paths := make([]string, len(tx.changes))
+	for i, change := range tx.changes {
+		paths[i] = change.path
+	}
+
+	return paths
+}
+
+// Backups returns the paths of all backups written by Commit
+func (tx *Transaction) Backups() []string {
+	backups := make([]string, 0, len(tx.changes))
+	for _, change := range tx.changes {
+		if change.backedUp {
+			backups = append(backups, change.path+tx.backupSuffix)
+		}
+	}
+
+	return backups
+}
+
+// Commit replaces all staged files. If one of them fails, the already replaced files are restored. With a
+// backup suffix, all backups are written first and a failing backup aborts the commit before any file is replaced.
+func (tx *Transaction) Commit() error {
+	for _, change := range tx.changes {
+		original, err := os.ReadFile(change.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("Failed to open '%s': %v", change.path, err)
+		}
+		change.original = original
+		change.existed = err == nil
+		if change.create && change.existed {
+			return fmt.Errorf("Failed creating '%s': file exists", change.path)
+		}
+	}
+
+	if tx.backupSuffix != "" {
+		for _, change := range tx.changes {
+			if err := change.backup(tx.backupSuffix); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, change := range tx.changes {
+		if err := change.write(change.content); err != nil {
+			if rollbackErr := tx.rollback(i); rollbackErr != nil {
+				rollbackErr.Err = err
+
+				return rollbackErr
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RollbackError is returned by Commit if a write failed and some of the files already replaced could not be
+// restored, which are left with their new content
+type RollbackError struct {
+	Err      error    // error of the failed write
+	Paths    []string // files which could not be restored
+	restores []error
+}
+
+func (err *RollbackError) Error() string {
+	messages := []string{err.Err.Error()}
+	for i, path := range err.Paths {
+		messages = append(messages, fmt.Sprintf("Failed restoring '%s': %v", path, err.restores[i]))
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (err *RollbackError) Unwrap() error {
+	return err.Err
+}
+
+// rollback restores the first n files in reverse order
+func (tx *Transaction) rollback(n int) *RollbackError {
+	failed := &RollbackError{}
+	for i := n - 1; i >= 0; i-- {
+		change := tx.changes[i]
+
+		var err error
+		if change.existed {
+			err = change.write(change.original)
+		} else {
+			err = os.Remove(change.path)
+		}
+		if err != nil {
+			failed.Paths = append(failed.Paths, change.path)
+			failed.restores = append(failed.restores, err)
+		}
+	}
+
+	if len(failed.Paths) > 0 {
+		return failed
+	}
+
+	return nil
+}
+
+func (change *fileChange) write(content []byte) error {
+	if !change.existed {
+		if err := change.writeNew(content); err != nil {
+			return fmt.Errorf("Failed writing file '%s': %v", change.path, err)
+		}
+
+		return nil
+	}
+
+	return ReplaceFile(change.path, content)
+}
+
+// writeNew writes content to a file which must not exist, e.g. created by another process since Commit read it
+func (change *fileChange) writeNew(content []byte) error {
+	if change.create {
+		if err := os.MkdirAll(filepath.Dir(change.path), 0700); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(change.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, change.perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(change.path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(change.path)
+		return err
+	}
+
+	// the umask may have cleared bits of perm
+	return os.Chmod(change.path, change.perm)
+}
+
+// backup writes the original content of an existing file to path+suffix, keeping its permissions
+func (change *fileChange) backup(suffix string) error {
+	if !change.existed {
+		return nil
+	}
+
+	info, err := os.Stat(change.path)
+	if err != nil {
+		return fmt.Errorf("Failed to stat '%s': %v", change.path, err)
+	}
+
+	path := change.path + suffix
+	if err := os.WriteFile(path, change.original, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("Failed writing backup '%s': %v", path, err)
+	}
+	// WriteFile keeps the mode of an existing backup
+	if err := os.Chmod(path, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("Failed to set permissions of '%s': %v", path, err)
+	}
+	change.backedUp = true
+
+	return nil
+}
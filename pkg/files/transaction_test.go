@@ -0,0 +1,103 @@
+package files
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransactionCommit(t *testing.T) {
+	dir := t.TempDir()
+	hosts := filepath.Join(dir, "hosts")
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(hosts, []byte("old hosts\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := NewTransaction()
+	tx.Backup(".bak")
+	tx.Stage(hosts, []byte("new hosts\n"))
+	tx.Stage(config, []byte("new config\n"))
+	tx.Create(filepath.Join(dir, "keys", "id_ed25519"), []byte("key\n"), 0600)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for path, want := range map[string]string{
+		hosts:                                    "new hosts\n",
+		hosts + ".bak":                           "old hosts\n",
+		config:                                   "new config\n",
+		filepath.Join(dir, "keys", "id_ed25519"): "key\n",
+	} {
+		content, err := os.ReadFile(path)
+		if err != nil || string(content) != want {
+			t.Errorf("%s: got %q (%v), want %q", path, content, err, want)
+		}
+	}
+	if backups := tx.Backups(); len(backups) != 1 || backups[0] != hosts+".bak" {
+		t.Errorf("got backups %v", backups)
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	tests := []struct {
+		name  string
+		stage func(tx *Transaction, dir string)
+	}{
+		{
+			name: "write fails",
+			stage: func(tx *Transaction, dir string) {
+				tx.Stage(filepath.Join(dir, "missing", "config"), []byte("new\n"))
+			},
+		},
+		{
+			name: "created file exists",
+			stage: func(tx *Transaction, dir string) {
+				tx.Create(filepath.Join(dir, "hosts"), []byte("new\n"), 0600)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			hosts := filepath.Join(dir, "hosts")
+			if err := os.WriteFile(hosts, []byte("old\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			tx := NewTransaction()
+			tx.Stage(hosts, []byte("new\n"))
+			tx.Stage(filepath.Join(dir, "new"), []byte("new\n"))
+			test.stage(tx, dir)
+
+			err := tx.Commit()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var rollbackErr *RollbackError
+			if errors.As(err, &rollbackErr) {
+				t.Errorf("rollback failed: %v", err)
+			}
+
+			if content, _ := os.ReadFile(hosts); string(content) != "old\n" {
+				t.Errorf("hosts not restored, got %q", content)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "new")); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("new file not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestRollbackError(t *testing.T) {
+	err := &RollbackError{Err: errors.New("Failed writing file 'b'"), Paths: []string{"a"}, restores: []error{errors.New("disk full")}}
+
+	if want := "Failed writing file 'b'\nFailed restoring 'a': disk full"; err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+	if errors.Unwrap(err) != err.Err {
+		t.Error("does not unwrap to the failed write")
+	}
+}
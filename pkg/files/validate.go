@@ -0,0 +1,40 @@
+package files
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Message)
+}
+
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// ValidateHosts checks content in hosts file format and returns ParseErrors for all invalid lines
+func ValidateHosts(content string) error {
+	_, err := parseHosts(strings.NewReader(content))
+
+	return err
+}
+
+// ValidateSSHConfig checks content in ssh_config format and returns ParseErrors for all invalid lines
+func ValidateSSHConfig(content string) error {
+	_, err := parseSSHConfig(strings.NewReader(content))
+
+	return err
+}
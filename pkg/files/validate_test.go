@@ -0,0 +1,96 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"valid", "127.0.0.1 localhost\n::1 localhost\n# comment\n\n10.0.0.1 web01 web01.lab # web\n", ""},
+		{"zoned IPv6", "fe80::1%lo0 localhost\n", ""},
+		{"domain address", "example.com web01\n", ""},
+		{"disabled line", "#[disabled] 10.0.0.1 web01\n", ""},
+		{"bad address", "10.0.0.1 web01\n10.0.0.0/24 web02\n", "line 2: '10.0.0.0/24' is not a valid IP address or domain"},
+		{"missing alias", "\n10.0.0.1\n", "line 2: missing host names for address '10.0.0.1'"},
+		{"bad alias", "10.0.0.1 web_01 -web\n", "line 1: '-web' is not a valid host name"},
+		{"numeric address out of range", "10.0.0.256 web02\n", "line 1: '10.0.0.256' is not a valid IP address or domain"},
+		{"numeric alias", "10.0.0.1 10.0.0.2\n", "line 1: '10.0.0.2' is not a valid host name"},
+		{"several lines", "x! y\n10.0.0.1\n", "line 1: 'x!' is not a valid IP address or domain\nline 2: missing host names for address '10.0.0.1'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateHosts(test.content)
+			if got := errorString(err); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateSSHConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"valid", "Host web01\n  HostName 10.0.0.1\n  Port 2222\n\nHost *\n  User=admin\n", ""},
+		{"disabled block", "#[disabled] Host web01\n#[disabled]   HostName 10.0.0.1\n", ""},
+		{"unknown keyword", "Host web01\n  Colour blue\n", "line 2: unknown keyword 'Colour'"},
+		{"missing argument", "Host web01\n  User\n", "line 2: missing argument for 'User'"},
+		{"bad port", "Host web01\n  port 99999\n", "line 2: bad port '99999'"},
+		{"single property", "Port 0", "line 1: bad port '0'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSSHConfig(test.content)
+			if got := errorString(err); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestReadInvalidLines covers files written by hand or other tools, whose invalid lines are kept
+func TestReadInvalidLines(t *testing.T) {
+	dir := t.TempDir()
+
+	hostsPath := filepath.Join(dir, "hosts")
+	if err := os.WriteFile(hostsPath, []byte("10.0.0.1 web01\n10.0.0.0/24 web02\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := GetHosts(hostsPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hosts.Entries()) != 2 {
+		t.Errorf("got %d entries, want 2", len(hosts.Entries()))
+	}
+
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, []byte("Host web01\n  Colour blue\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sshConfig, err := GetSSHConfig(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := sshConfig.String(); got != "Host web01\n  Colour blue\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}